@@ -0,0 +1,325 @@
+package board
+
+import "math/bits"
+
+// A set of squares, with bit (row*8 + col) set for each member
+type bitboard uint64
+
+func (bb bitboard) has(square int) bool {
+	return bb&(1<<square) != 0
+}
+
+// Index of the lowest set square, 64 if empty
+func (bb bitboard) first() int {
+	return bits.TrailingZeros64(uint64(bb))
+}
+
+// Index of the highest set square, -1 if empty
+func (bb bitboard) last() int {
+	return 63 - bits.LeadingZeros64(uint64(bb))
+}
+
+// Calls callback for each square in the set, lowest first
+func (bb bitboard) forEach(callback func(square int)) {
+	for bb != 0 {
+		square := bb.first()
+		bb &= bb - 1
+		callback(square)
+	}
+}
+
+func squareOf(coord Coordinate) int {
+	row, col := coord.GetCoords()
+	return int(row)*8 + int(col)
+}
+
+func coordOf(square int) Coordinate {
+	return CreateCoordInt(square/8, square%8)
+}
+
+// Per color, per piece type occupancy of a board,
+// kept in sync with the [8][8]Piece representation by Game.Set
+type bitboards struct {
+	pieces   [2][6]bitboard
+	colors   [2]bitboard
+	occupied bitboard
+}
+
+func coordsOf(bb bitboard) []Coordinate {
+	result := []Coordinate{}
+	bb.forEach(func(square int) {
+		result = append(result, coordOf(square))
+	})
+	return result
+}
+
+func colorIndex(piece Piece) int {
+	if piece.GetColor() == Black {
+		return 1
+	}
+
+	return 0
+}
+
+func typeIndex(piece Piece) int {
+	switch piece.GetType() {
+	case Pawn:
+		return 0
+	case Knight:
+		return 1
+	case Bishop:
+		return 2
+	case Rook:
+		return 3
+	case Queen:
+		return 4
+	case King:
+		return 5
+	}
+
+	return -1
+}
+
+const (
+	pawnIndex = iota
+	knightIndex
+	bishopIndex
+	rookIndex
+	queenIndex
+	kingIndex
+)
+
+func newBitboards(board *[8][8]Piece) *bitboards {
+	result := &bitboards{}
+
+	for row := range board {
+		for col, piece := range board[row] {
+			result.add(row*8+col, piece)
+		}
+	}
+
+	return result
+}
+
+func (bb *bitboards) add(square int, piece Piece) {
+	if piece == 0 {
+		return
+	}
+
+	mask := bitboard(1) << square
+	color := colorIndex(piece)
+	bb.pieces[color][typeIndex(piece)] |= mask
+	bb.colors[color] |= mask
+	bb.occupied |= mask
+}
+
+func (bb *bitboards) remove(square int, piece Piece) {
+	if piece == 0 {
+		return
+	}
+
+	mask := ^(bitboard(1) << square)
+	color := colorIndex(piece)
+	bb.pieces[color][typeIndex(piece)] &= mask
+	bb.colors[color] &= mask
+	bb.occupied &= mask
+}
+
+func (bb *bitboards) king(color int) int {
+	return bb.pieces[color][kingIndex].first()
+}
+
+// All pieces (of either color) attacking square, given the occupancy occ
+func (bb *bitboards) attackers(square int, occ bitboard) bitboard {
+	white, black := bb.pieces[0], bb.pieces[1]
+	diagonal := white[bishopIndex] | white[queenIndex] | black[bishopIndex] | black[queenIndex]
+	orthogonal := white[rookIndex] | white[queenIndex] | black[rookIndex] | black[queenIndex]
+
+	return knightAttacks[square]&(white[knightIndex]|black[knightIndex]) |
+		kingAttacks[square]&(white[kingIndex]|black[kingIndex]) |
+		pawnAttacks[0][square]&black[pawnIndex] |
+		pawnAttacks[1][square]&white[pawnIndex] |
+		bishopAttacks(square, occ)&diagonal |
+		rookAttacks(square, occ)&orthogonal
+}
+
+func (bb *bitboards) attacked(square int, by int) bool {
+	return bb.attackers(square, bb.occupied)&bb.colors[by] != 0
+}
+
+// Enemy pieces attacking the king of color, empty if there is no king
+func (bb *bitboards) checkers(color int) bitboard {
+	king := bb.king(color)
+	if king == 64 {
+		return 0
+	}
+
+	return bb.attackers(king, bb.occupied) & bb.colors[1-color]
+}
+
+// Pieces of color that are the only piece between their king and an enemy slider
+func (bb *bitboards) pinned(color int) bitboard {
+	king := bb.king(color)
+	if king == 64 {
+		return 0
+	}
+
+	them := bb.pieces[1-color]
+	snipers := bishopAttacks(king, 0)&(them[bishopIndex]|them[queenIndex]) |
+		rookAttacks(king, 0)&(them[rookIndex]|them[queenIndex])
+
+	var result bitboard
+	snipers.forEach(func(sniper int) {
+		blockers := between[king][sniper] & bb.occupied
+		if blockers != 0 && blockers&(blockers-1) == 0 && blockers&bb.colors[color] != 0 {
+			result |= blockers
+		}
+	})
+
+	return result
+}
+
+// Applies move to the bitboards only, used to test legality
+// without touching the rest of the game.
+func (bb *bitboards) apply(move Move) {
+	from, to := squareOf(move.From), squareOf(move.To)
+
+	if move.IsCastle() {
+		king, rook := move.castleSquares()
+
+		bb.remove(from, move.Piece)
+		bb.remove(to, move.Capture)
+		bb.add(squareOf(king), move.Piece)
+		bb.add(squareOf(rook), move.Capture)
+		return
+	}
+
+	bb.remove(from, move.Piece)
+
+	if move.isEnPassant {
+		fromRow, _ := move.From.GetCoords()
+		_, toCol := move.To.GetCoords()
+		bb.remove(int(fromRow)*8+int(toCol), Pawn|(^move.Piece).GetColor())
+	} else {
+		bb.remove(to, move.Capture)
+	}
+
+	if promotion := move.Promotion(); promotion != 0 {
+		bb.add(to, promotion)
+	} else {
+		bb.add(to, move.Piece)
+	}
+}
+
+var (
+	knightAttacks [64]bitboard
+	kingAttacks   [64]bitboard
+	// Squares attacked by a pawn of the given color index
+	pawnAttacks [2][64]bitboard
+
+	// Squares along a direction, not including the origin
+	rays [8][64]bitboard
+	// Squares strictly between two squares sharing a line, empty otherwise
+	between [64][64]bitboard
+	// The whole line (edge to edge) through two squares, empty if they don't share one
+	lines [64][64]bitboard
+)
+
+// Directions in (row, col), the first four increase the square index
+var directions = [8][2]int{
+	{1, 0}, {0, 1}, {1, 1}, {1, -1},
+	{-1, 0}, {0, -1}, {-1, -1}, {-1, 1},
+}
+
+const (
+	north = iota
+	east
+	northEast
+	northWest
+	south
+	west
+	southWest
+	southEast
+)
+
+func init() {
+	offset := func(square, row, col int) (int, bool) {
+		r, c := square/8+row, square%8+col
+		if r < 0 || r > 7 || c < 0 || c > 7 {
+			return 0, false
+		}
+		return r*8 + c, true
+	}
+
+	for square := 0; square < 64; square++ {
+		for _, o := range [][2]int{{-2, 1}, {-1, 2}, {1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}} {
+			if target, ok := offset(square, o[0], o[1]); ok {
+				knightAttacks[square] |= 1 << target
+			}
+		}
+
+		for _, o := range directions {
+			if target, ok := offset(square, o[0], o[1]); ok {
+				kingAttacks[square] |= 1 << target
+			}
+		}
+
+		for color, dir := range []int{1, -1} {
+			for _, col := range []int{-1, 1} {
+				if target, ok := offset(square, dir, col); ok {
+					pawnAttacks[color][square] |= 1 << target
+				}
+			}
+		}
+
+		for dir, o := range directions {
+			current := square
+			for {
+				target, ok := offset(current, o[0], o[1])
+				if !ok {
+					break
+				}
+				rays[dir][square] |= 1 << target
+				current = target
+			}
+		}
+	}
+
+	for square := 0; square < 64; square++ {
+		for dir := range directions {
+			line := rays[dir][square] | rays[(dir+4)%8][square] | 1<<square
+			rays[dir][square].forEach(func(target int) {
+				between[square][target] = rays[dir][square] &^ rays[dir][target] &^ (1 << target)
+				lines[square][target] = line
+			})
+		}
+	}
+}
+
+// Squares attacked along a ray, stopping at (and including) the first blocker
+func rayAttacks(square int, dir int, occ bitboard) bitboard {
+	attacks := rays[dir][square]
+	blockers := attacks & occ
+	if blockers == 0 {
+		return attacks
+	}
+
+	var blocker int
+	if dir < south {
+		blocker = blockers.first()
+	} else {
+		blocker = blockers.last()
+	}
+
+	return attacks ^ rays[dir][blocker]
+}
+
+func bishopAttacks(square int, occ bitboard) bitboard {
+	return rayAttacks(square, northEast, occ) | rayAttacks(square, northWest, occ) |
+		rayAttacks(square, southWest, occ) | rayAttacks(square, southEast, occ)
+}
+
+func rookAttacks(square int, occ bitboard) bitboard {
+	return rayAttacks(square, north, occ) | rayAttacks(square, east, occ) |
+		rayAttacks(square, south, occ) | rayAttacks(square, west, occ)
+}
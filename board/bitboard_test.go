@@ -0,0 +1,217 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func squaresOf(coords ...string) bitboard {
+	var result bitboard
+	for _, coord := range coords {
+		result |= 1 << squareOf(CreateCoordAlgebra(coord))
+	}
+	return result
+}
+
+func TestAttackTables(t *testing.T) {
+	tests := map[string]struct {
+		attacks  bitboard
+		expected bitboard
+	}{
+		"Knight a1": {
+			attacks:  knightAttacks[squareOf(CreateCoordAlgebra("a1"))],
+			expected: squaresOf("b3", "c2"),
+		},
+		"Knight e4": {
+			attacks:  knightAttacks[squareOf(CreateCoordAlgebra("e4"))],
+			expected: squaresOf("d2", "f2", "c3", "g3", "c5", "g5", "d6", "f6"),
+		},
+		"King h8": {
+			attacks:  kingAttacks[squareOf(CreateCoordAlgebra("h8"))],
+			expected: squaresOf("g8", "g7", "h7"),
+		},
+		"White Pawn a2": {
+			attacks:  pawnAttacks[0][squareOf(CreateCoordAlgebra("a2"))],
+			expected: squaresOf("b3"),
+		},
+		"Black Pawn e7": {
+			attacks:  pawnAttacks[1][squareOf(CreateCoordAlgebra("e7"))],
+			expected: squaresOf("d6", "f6"),
+		},
+		"Rook a1 Empty": {
+			attacks:  rookAttacks(squareOf(CreateCoordAlgebra("a1")), 0),
+			expected: squaresOf("a2", "a3", "a4", "a5", "a6", "a7", "a8", "b1", "c1", "d1", "e1", "f1", "g1", "h1"),
+		},
+		"Rook d4 Blocked": {
+			attacks:  rookAttacks(squareOf(CreateCoordAlgebra("d4")), squaresOf("d6", "b4", "d2", "g4")),
+			expected: squaresOf("d5", "d6", "c4", "b4", "d3", "d2", "e4", "f4", "g4"),
+		},
+		"Bishop c1 Blocked": {
+			attacks:  bishopAttacks(squareOf(CreateCoordAlgebra("c1")), squaresOf("b2", "e3")),
+			expected: squaresOf("b2", "d2", "e3"),
+		},
+		"Between a1 d4": {
+			attacks:  between[squareOf(CreateCoordAlgebra("a1"))][squareOf(CreateCoordAlgebra("d4"))],
+			expected: squaresOf("b2", "c3"),
+		},
+		"Between h5 e5": {
+			attacks:  between[squareOf(CreateCoordAlgebra("h5"))][squareOf(CreateCoordAlgebra("e5"))],
+			expected: squaresOf("f5", "g5"),
+		},
+		"Between Unaligned": {
+			attacks:  between[squareOf(CreateCoordAlgebra("a1"))][squareOf(CreateCoordAlgebra("b3"))],
+			expected: 0,
+		},
+		"Line c2 d3": {
+			attacks:  lines[squareOf(CreateCoordAlgebra("c2"))][squareOf(CreateCoordAlgebra("d3"))],
+			expected: squaresOf("b1", "c2", "d3", "e4", "f5", "g6", "h7"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.attacks != test.expected {
+				t.Errorf("expected %064b, got %064b", test.expected, test.attacks)
+			}
+		})
+	}
+}
+
+func TestAttacked(t *testing.T) {
+	game, err := FromFEN("4k3/8/8/3p4/8/2N5/8/R3K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		square   string
+		by       int
+		expected bool
+	}{
+		"Rook File":     {square: "a8", by: 0, expected: true},
+		"Knight":        {square: "d5", by: 0, expected: true},
+		"Black Pawn":    {square: "c4", by: 1, expected: true},
+		"Black King":    {square: "d7", by: 1, expected: true},
+		"Unattacked":    {square: "h5", by: 0, expected: false},
+		"Pawn Push":     {square: "d4", by: 1, expected: false},
+		"Behind Knight": {square: "e2", by: 1, expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := game.bits.attacked(squareOf(CreateCoordAlgebra(test.square)), test.by)
+			if result != test.expected {
+				t.Errorf("expected %v to be attacked: %v, got %v", test.square, test.expected, result)
+			}
+		})
+	}
+}
+
+func TestCastlingLegality(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected []string
+	}{
+		"Both Sides": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			expected: []string{"h1", "a1"},
+		},
+		"Out Of Check": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K1qR w KQkq - 0 1",
+			expected: []string{},
+		},
+		"Through Check": {
+			fen:      "r3k2r/8/8/8/8/8/5p2/R3K2R w KQkq - 0 1",
+			expected: []string{},
+		},
+		"Into Check": {
+			fen:      "r3k2r/8/1b6/8/8/8/8/R3K2R w KQkq - 0 1",
+			expected: []string{"a1"},
+		},
+		"Attacked Rook": {
+			fen:      "r3k2r/8/2b5/8/8/8/8/R3K2R w KQkq - 0 1",
+			expected: []string{"h1", "a1"},
+		},
+		"Attacked b-File": {
+			fen:      "1r2k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			expected: []string{"h1", "a1"},
+		},
+		"Attacked d-File": {
+			fen:      "3rk2r/8/8/8/8/8/8/R3K2R w KQk - 0 1",
+			expected: []string{"h1"},
+		},
+		"Attacked g-File": {
+			fen:      "r3k1r1/8/8/8/8/8/8/R3K2R w KQq - 0 1",
+			expected: []string{"a1"},
+		},
+		"Missing Rook": {
+			fen:      "r3k2r/8/8/8/8/8/8/4K2R w KQkq - 0 1",
+			expected: []string{"h1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			castles := []string{}
+			for _, move := range game.GetMoves() {
+				if move.IsCastle() {
+					castles = append(castles, move.To.GetAlgebra())
+				}
+			}
+
+			if strings.Join(castles, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("expected castles %v, got %v", test.expected, castles)
+			}
+		})
+	}
+}
+
+// The bitboards should always describe the same position as Board
+func TestBitboardSync(t *testing.T) {
+	for name, test := range getPerfData() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			game, err := FromFEN(test.FEN)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkBitboards(t, game, 3)
+		})
+	}
+
+	t.Run("Set", func(t *testing.T) {
+		game, err := FromFEN(START_POSITION)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		game.Set(CreateCoordAlgebra("e4"), White|Queen)
+		game.Move(CreateCoordAlgebra("g1"), CreateCoordAlgebra("f3"))
+
+		if *game.bits != *newBitboards(game.Board) {
+			t.Error("bitboards are out of sync after Set")
+		}
+	})
+}
+
+func checkBitboards(t *testing.T, game *Game, depth int) {
+	if *game.bits != *newBitboards(game.Board) {
+		t.Fatalf("bitboards are out of sync with board after %v", game.Moves)
+	}
+
+	if depth == 0 {
+		return
+	}
+
+	for _, move := range game.GetMoves() {
+		game.MakeMove(move)
+		checkBitboards(t, game, depth-1)
+		game.UndoMove()
+	}
+}
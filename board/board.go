@@ -1,11 +1,14 @@
 package board
 
 import (
-	"errors"
 	"fmt"
-	"strconv"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/msws/chess/tt"
 )
 
 // Single byte to store 2D coordinates,
@@ -65,27 +68,45 @@ func (board Game) GetStr(coord string) Piece {
 	return board.Get(CreateCoordAlgebra(coord))
 }
 
-func (board Game) Set(coord Coordinate, piece Piece) {
+// Sets the piece at coord, keeping the bitboards and hash up to date
+func (board *Game) Set(coord Coordinate, piece Piece) {
+	// The piece may change whether en passant is possible, which is part of the hash
+	board.hash ^= board.stateHash()
+	board.put(coord, piece)
+	board.hash ^= board.stateHash()
+}
+
+// Sets the piece at coord on the board and bitboards, but not the hash
+func (board Game) setPiece(coord Coordinate, piece Piece) {
 	row, col := coord.GetCoords()
+
+	if board.bits != nil {
+		square := squareOf(coord)
+		board.bits.remove(square, board.Board[row][col])
+		board.bits.add(square, piece)
+	}
+
 	board.Board[row][col] = piece
 }
 
-func (board Game) Move(from Coordinate, to Coordinate) {
-	fromRow, fromCol := from.GetCoords()
-	toRow, toCol := to.GetCoords()
-	board.Board[toRow][toCol] = board.Board[fromRow][fromCol]
-	board.Board[fromRow][fromCol] = 0
+func (board *Game) Move(from Coordinate, to Coordinate) {
+	board.Set(to, board.Get(from))
+	board.Set(from, 0)
 }
 
 func (board *Game) MakeMove(move Move) {
 	board.WhiteCastleHistory = append(board.WhiteCastleHistory, board.WhiteCastling)
 	board.BlackCastleHistory = append(board.BlackCastleHistory, board.BlackCastling)
-	board.PreviousEnpassant = board.EnPassant
+	board.EnPassantHistory = append(board.EnPassantHistory, board.EnPassant)
+	board.HalfMoveHistory = append(board.HalfMoveHistory, board.HalfMoves)
+	board.HashHistory = append(board.HashHistory, board.hash)
+	board.hash ^= board.stateHash()
+
 	captured := board.Get(move.To)
 	move.Capture = captured
 
-	board.Move(move.From, move.To)
-	_, fromCol := move.From.GetCoords()
+	board.put(move.From, 0)
+	board.put(move.To, move.Piece)
 
 	if move.Piece.GetType() == Pawn {
 		toRow, _ := move.To.GetCoords()
@@ -93,23 +114,29 @@ func (board *Game) MakeMove(move Move) {
 			if move.promotionTo == 0 {
 				move.promotionTo = Queen | move.Piece.GetColor()
 			}
-			board.Set(move.To, move.promotionTo|move.Piece.GetColor())
+			board.put(move.To, move.promotionTo|move.Piece.GetColor())
 		}
 
 	}
 	board.applyEnPassant(&move)
 
+	if move.Piece.GetType() == Pawn || move.IsCapture() {
+		board.HalfMoves = 0
+	} else {
+		board.HalfMoves++
+	}
+
+	if move.Piece.GetColor() == Black {
+		board.FullMoves++
+	}
+
 	castlability := &board.WhiteCastling
 	if move.Piece.GetColor() == Black {
 		castlability = &board.BlackCastling
 	}
 
 	if move.Piece.GetType() == Rook {
-		if fromCol == 0 {
-			castlability.QueenSide = false
-		} else if fromCol == 7 {
-			castlability.KingSide = false
-		}
+		board.revokeCastling(castlability, move.Piece, move.From)
 	}
 
 	if move.Piece.GetType() == King {
@@ -121,7 +148,7 @@ func (board *Game) MakeMove(move Move) {
 		}
 	}
 
-	if move.Capture.GetType() == Rook {
+	if move.Capture.GetType() == Rook && !move.IsCastle() {
 		// If capturing a rook that would've otherwise allowed castling
 		// make sure we update the enemy's castlablity
 
@@ -130,74 +157,94 @@ func (board *Game) MakeMove(move Move) {
 			enemyCastling = &board.WhiteCastling
 		}
 
-		toRow, toCol := move.To.GetCoords()
-
-		enemyHomeRow := 7
-		if move.Capture.GetColor() == White {
-			enemyHomeRow = 0
-		}
-
-		if toRow == byte(enemyHomeRow) {
-			if toCol == 0 {
-				enemyCastling.QueenSide = false
-			} else if toCol == 7 {
-				enemyCastling.KingSide = false
-			}
-		}
+		board.revokeCastling(enemyCastling, move.Capture, move.To)
 	}
 
 	board.Active = (^board.Active).GetColor()
+	board.hash ^= zobrist.black ^ board.stateHash()
 	board.Moves = append(board.Moves, move)
 	// return move
 }
 
-func (board *Game) UndoMove() {
-	move := board.Moves[len(board.Moves)-1]
-	board.Set(move.To, move.Capture)
-	board.Set(move.From, move.Piece)
+// Revokes the castling right that rook, on or leaving coord, would castle with
+func (board *Game) revokeCastling(castling *Castling, rook Piece, coord Coordinate) {
+	row, col := coord.GetCoords()
+	homeRow := byte(0)
+	if rook.GetColor() == Black {
+		homeRow = 7
+	}
 
-	castling := &board.WhiteCastling
-	if move.Piece.GetColor() == Black {
-		castling = &board.BlackCastling
+	if row != homeRow {
+		return
 	}
 
-	toRow, toCol := move.To.GetCoords()
-	fromRow, _ := move.From.GetCoords()
+	if int(col) == board.rookFile(rook, false) {
+		castling.QueenSide = false
+	} else if int(col) == board.rookFile(rook, true) {
+		castling.KingSide = false
+	}
+}
+
+// File of the rook color castles with on the given side
+func (board Game) rookFile(color Piece, kingSide bool) int {
+	side := 0
+	if kingSide {
+		side = 1
+	}
+
+	if board.Chess960 {
+		return board.rookFiles[colorIndex(color)][side]
+	}
+
+	return side * 7
+}
+
+func (board *Game) UndoMove() {
+	move := board.Moves[len(board.Moves)-1]
+	board.hash ^= board.stateHash()
 
 	if move.IsCastle() {
-		if toCol == 0 {
-			castling.QueenSide = true
-			board.Set(CreateCoordInt(int(toRow), 1), 0)
-			board.Set(CreateCoordInt(int(toRow), 2), 0)
-			board.Set(CreateCoordInt(int(toRow), 3), 0)
-		} else {
-			castling.KingSide = true
-			board.Set(CreateCoordInt(int(toRow), 5), 0)
-			board.Set(CreateCoordInt(int(toRow), 6), 0)
-		}
+		// Lift both pieces first, in Chess960 either may
+		// have landed on the other's starting square
+		king, rook := move.castleSquares()
+		board.put(king, 0)
+		board.put(rook, 0)
 	}
 
+	board.put(move.To, move.Capture)
+	board.put(move.From, move.Piece)
+
+	_, toCol := move.To.GetCoords()
+	fromRow, _ := move.From.GetCoords()
+
 	if move.isEnPassant {
-		board.Set(move.To, 0)
+		board.put(move.To, 0)
 		captured := CreateCoordByte(fromRow, toCol)
-		board.Set(captured, move.Capture)
-		board.EnPassant = &move.To
-	} else {
-		board.EnPassant = board.PreviousEnpassant
+		board.put(captured, move.Capture)
 	}
 
 	board.Active = (^board.Active).GetColor()
 	board.Moves = board.Moves[0 : len(board.Moves)-1]
 	board.WhiteCastling = board.WhiteCastleHistory[len(board.WhiteCastleHistory)-1]
 	board.BlackCastling = board.BlackCastleHistory[len(board.BlackCastleHistory)-1]
+	board.EnPassant = board.EnPassantHistory[len(board.EnPassantHistory)-1]
+	board.HalfMoves = board.HalfMoveHistory[len(board.HalfMoveHistory)-1]
+
+	if move.Piece.GetColor() == Black {
+		board.FullMoves--
+	}
 
 	board.WhiteCastleHistory = board.WhiteCastleHistory[0 : len(board.WhiteCastleHistory)-1]
 	board.BlackCastleHistory = board.BlackCastleHistory[0 : len(board.BlackCastleHistory)-1]
+	board.EnPassantHistory = board.EnPassantHistory[0 : len(board.EnPassantHistory)-1]
+	board.HalfMoveHistory = board.HalfMoveHistory[0 : len(board.HalfMoveHistory)-1]
+	board.HashHistory = board.HashHistory[0 : len(board.HashHistory)-1]
+	board.hash ^= zobrist.black ^ board.stateHash()
 }
 
 func (board *Game) applyEnPassant(move *Move) {
 	toRow, toCol := move.To.GetCoords()
-	fromRow, fromCol := move.From.GetCoords()
+	fromRow, _ := move.From.GetCoords()
 	if move.Piece.GetType() != Pawn {
 		board.EnPassant = nil
 		return
@@ -210,37 +257,27 @@ func (board *Game) applyEnPassant(move *Move) {
 		if board.Get(captured) != enemyPiece {
 			panic(fmt.Sprintf("En Passanted non-enemy piece on %v, got %v, expected %v", captured, board.Get(captured), enemyPiece))
 		}
-		board.Set(captured, 0)
+		board.put(captured, 0)
 		move.Capture = enemyPiece
 		move.isEnPassant = true
 	}
 	board.EnPassant = nil
 
+	// Recorded after every double push, as FEN does,
+	// whether or not an enemy pawn is there to take it
 	if (toRow == 3 || toRow == 4) && (fromRow == 1 || fromRow == 6) {
 		rowDir := (int(toRow) - int(fromRow)) / 2
-		if fromCol > 0 && board.Get(move.To.Add(0, -1)) == enemyPiece {
-			enpassant := move.From.Add(rowDir, 0)
-			board.EnPassant = &enpassant
-		} else if fromCol < 7 && board.Get(move.To.Add(0, 1)) == enemyPiece {
-			enpassant := move.From.Add(rowDir, 0)
-			board.EnPassant = &enpassant
-		}
+		enpassant := move.From.Add(rowDir, 0)
+		board.EnPassant = &enpassant
 	}
 }
 
-func (board Game) applyCastle(move Move) {
-	kingSquare := 2
-	rookSquare := 3
-	castleRow, castleCol := move.To.GetCoords()
+func (board *Game) applyCastle(move Move) {
+	king, rook := move.castleSquares()
 
-	if castleCol == 7 {
-		kingSquare = 6
-		rookSquare = 5
-	}
-
-	board.Set(move.To, 0)
-	board.Set(CreateCoordByte(castleRow, byte(kingSquare)), move.Piece)
-	board.Set(CreateCoordByte(castleRow, byte(rookSquare)), move.Capture)
+	board.put(move.To, 0)
+	board.put(king, move.Piece)
+	board.put(rook, move.Capture)
 }
 
 func (board *Game) MakeMoveStr(str string) {
@@ -269,6 +306,15 @@ type Game struct {
 	WhiteCastling Castling
 	BlackCastling Castling
 
+	// Whether castling follows the Chess960 rules, where the king and
+	// rooks may start on any file. FEN castling rights are written
+	// Shredder-FEN style, as the files of the rooks.
+	Chess960 bool
+
+	// Files of the rooks castled with in Chess960,
+	// by color index then queen side, king side
+	rookFiles [2][2]int
+
 	// The square that can be captured en passant
 	EnPassant *Coordinate
 
@@ -281,7 +327,41 @@ type Game struct {
 	Moves              []Move
 	WhiteCastleHistory []Castling
 	BlackCastleHistory []Castling
-	PreviousEnpassant  *Coordinate
+	EnPassantHistory   []*Coordinate
+	HalfMoveHistory    []int
+
+	// Hashes of the positions before each move, see RepetitionCount
+	HashHistory []uint64
+
+	// Zobrist hash of the position, see Hash
+	hash uint64
+
+	// Bitboard representation of Board, shared between copies
+	// of the game in the same way that Board is
+	bits *bitboards
+}
+
+// Returns a deep copy of the game that shares no state with the original,
+// so that the two can be played on independently (e.g. from different goroutines).
+func (board Game) Clone() *Game {
+	clone := board
+	pieces := *board.Board
+	clone.Board = &pieces
+	clone.bits = newBitboards(clone.Board)
+
+	if board.EnPassant != nil {
+		enPassant := *board.EnPassant
+		clone.EnPassant = &enPassant
+	}
+
+	clone.Moves = append([]Move(nil), board.Moves...)
+	clone.WhiteCastleHistory = append([]Castling(nil), board.WhiteCastleHistory...)
+	clone.BlackCastleHistory = append([]Castling(nil), board.BlackCastleHistory...)
+	clone.EnPassantHistory = append([]*Coordinate(nil), board.EnPassantHistory...)
+	clone.HalfMoveHistory = append([]int(nil), board.HalfMoveHistory...)
+	clone.HashHistory = append([]uint64(nil), board.HashHistory...)
+
+	return &clone
 }
 
 func (board Game) Equal(other Game) bool {
@@ -310,55 +390,94 @@ func (board Game) PrettyPrint() string {
 	return builder.String()
 }
 
+// Parses a position in Forsyth-Edwards Notation. Every field is
+// checked for syntax, see ValidateFEN to also check the position is legal.
+// Chess960 castling rights may be given as Shredder-FEN rook files,
+// as written by ToFEN, if those files hold the rooks.
+// All errors are of type *FENError.
 func FromFEN(str string) (*Game, error) {
-	result := Game{}
-
 	records := strings.Split(strings.TrimSpace(str), " ")
 
 	if len(records) != 6 {
-		return nil, errors.New("malformed FEN string, expected 6 records")
+		return nil, &FENError{Err: fmt.Errorf("%w, got %d", ErrRecordCount, len(records))}
+	}
+
+	return fromRecords(records, false)
+}
+
+// Parses a position like FromFEN, but tolerates the forms found in
+// databases and EPD files: fields may be separated by any whitespace,
+// missing clocks default to "0 1", and castling rights may be given
+// as X-FEN or Shredder-FEN rook files (e.g. HAha).
+func FromFENLenient(str string) (*Game, error) {
+	records := strings.Fields(str)
+
+	switch len(records) {
+	case 4:
+		records = append(records, "0", "1")
+	case 5:
+		records = append(records, "1")
+	case 6:
+	default:
+		return nil, &FENError{Err: fmt.Errorf("%w (or 4 without clocks), got %d", ErrRecordCount, len(records))}
 	}
 
+	return fromRecords(records, true)
+}
+
+func fromRecords(records []string, lenient bool) (*Game, error) {
+	result := Game{rookFiles: [2][2]int{{0, 7}, {0, 7}}}
+
 	board, err := GenerateBoard(records[0])
 
 	if err != nil {
-		return &result, err
+		return nil, err
 	}
 
 	result.Board = board
+	result.bits = newBitboards(board)
 
-	switch records[1][0] {
-	case 'w':
+	switch records[1] {
+	case "w":
 		result.Active = White
-	case 'b':
+	case "b":
 		result.Active = Black
 	default:
-		return &result, fmt.Errorf("invalid active color %c", records[1][0])
+		return nil, &FENError{Field: 2, Err: fmt.Errorf("%w %q", ErrBadActiveColor, records[1])}
 	}
 
-	result.WhiteCastling = formatCastling(records[2], White)
-	result.BlackCastling = formatCastling(records[2], Black)
+	if lenient {
+		err = result.parseXFENCastling(records[2], false)
+	} else if strings.ContainsAny(records[2], "ABCDEFGHabcdefgh") {
+		// Shredder-FEN, as ToFEN writes for Chess960
+		err = result.parseXFENCastling(records[2], true)
+	} else {
+		result.WhiteCastling, result.BlackCastling, err = parseCastling(records[2])
+	}
 
-	if records[3][0] != '-' {
-		coords := CreateCoordAlgebra(records[3])
-		result.EnPassant = &coords
+	if err != nil {
+		return nil, err
 	}
 
-	halfMoves, err := strconv.Atoi(records[4])
+	result.EnPassant, err = parseEnPassant(records[3], result.Active)
 
 	if err != nil {
-		return &result, err
+		return nil, err
 	}
 
-	result.HalfMoves = halfMoves
+	result.HalfMoves, err = parseClock(records[4], 5)
+
+	if err != nil {
+		return nil, err
+	}
 
-	fullMoves, err := strconv.Atoi(records[5])
+	result.FullMoves, err = parseClock(records[5], 6)
 
 	if err != nil {
-		return &result, err
+		return nil, err
 	}
 
-	result.FullMoves = fullMoves
+	result.hash = result.computeHash()
 
 	return &result, nil
 }
@@ -376,20 +495,34 @@ func (board Game) ToFEN() string {
 	result.WriteRune(' ')
 	oldLen := result.Len()
 
-	if board.WhiteCastling.KingSide {
-		result.WriteRune('K')
+	rights := []struct {
+		allowed  bool
+		color    Piece
+		kingSide bool
+	}{
+		{board.WhiteCastling.KingSide, White, true},
+		{board.WhiteCastling.QueenSide, White, false},
+		{board.BlackCastling.KingSide, Black, true},
+		{board.BlackCastling.QueenSide, Black, false},
 	}
 
-	if board.WhiteCastling.QueenSide {
-		result.WriteRune('Q')
-	}
+	for _, right := range rights {
+		if !right.allowed {
+			continue
+		}
 
-	if board.BlackCastling.KingSide {
-		result.WriteRune('k')
-	}
+		letter := 'Q'
+		if board.Chess960 {
+			letter = 'A' + rune(board.rookFile(right.color, right.kingSide))
+		} else if right.kingSide {
+			letter = 'K'
+		}
 
-	if board.BlackCastling.QueenSide {
-		result.WriteRune('q')
+		if right.color == Black {
+			letter = unicode.ToLower(letter)
+		}
+
+		result.WriteRune(letter)
 	}
 
 	if oldLen == result.Len() {
@@ -413,54 +546,89 @@ func (board Game) ToFEN() string {
 	return result.String()
 }
 
-var perftCache sync.Map
+// Size of the table shared by calls to Perft, in megabytes
+const perftTableSize = 64
+
+// Allocated on first use, so that programs that never
+// call Perft do not pay for the memory
+var perftTable = sync.OnceValue(func() *tt.Table[Move] {
+	return tt.New[Move](perftTableSize)
+})
 
+// Counts the leaf nodes of the legal move tree to the given depth.
+// Subtree counts are cached by hash in a shared transposition table,
+// with the node count stored as the entry's score.
 func (game Game) Perft(depth int) int {
-	if depth == 0 {
-		return 1
-	}
+	return game.PerftWithTable(depth, perftTable())
+}
 
-	fen := game.ToFEN()
-	parts := strings.Split(fen, " ")
-	fen = parts[0] + parts[1] + parts[2] + parts[3] + string(rune(depth))
-	fen += strconv.Itoa(depth)
+// Counts the same nodes as Perft, splitting the root moves between
+// the given number of goroutines, each searching its own Clone of the game.
+// A workers count below 1 uses one goroutine per CPU.
+// Subtree counts are cached in table, which may be nil to disable caching.
+func (game Game) PerftParallel(depth, workers int, table *tt.Table[Move]) int {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
 
-	val, ok := perftCache.Load(fen)
-	if ok {
-		return val.(int)
+	if depth <= 1 || workers == 1 {
+		return game.PerftWithTable(depth, table)
 	}
 
-	var nodes int
+	moves := make(chan Move)
+	var nodes atomic.Int64
+	var wg sync.WaitGroup
 
-	moves := game.GetMoves()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(clone *Game) {
+			defer wg.Done()
 
-	if depth == 1 {
-		perftCache.Store(fen, len(moves))
-		return len(moves)
+			for move := range moves {
+				clone.MakeMove(move)
+				nodes.Add(int64(clone.PerftWithTable(depth-1, table)))
+				clone.UndoMove()
+			}
+		}(game.Clone())
 	}
 
-	for _, move := range moves {
-		game.MakeMove(move)
-		nodes += game.Perft(depth - 1)
-		game.UndoMove()
+	for _, move := range game.GetMoves() {
+		moves <- move
 	}
+	close(moves)
+	wg.Wait()
 
-	perftCache.Store(fen, nodes)
-	return nodes
+	return int(nodes.Load())
 }
 
-func formatCastling(str string, color Piece) Castling {
-	if color == White {
-		return Castling{
-			QueenSide: strings.Contains(str, "Q"),
-			KingSide:  strings.Contains(str, "K"),
+// Like Perft, but caches subtree counts in table instead of the shared one.
+// A nil table disables caching.
+func (game Game) PerftWithTable(depth int, table *tt.Table[Move]) int {
+	if depth <= 0 {
+		return 1
+	}
+
+	if table != nil {
+		if entry, ok := table.Probe(game.hash); ok && entry.Depth == depth {
+			return entry.Score
 		}
-	} else if color == Black {
-		return Castling{
-			QueenSide: strings.Contains(str, "q"),
-			KingSide:  strings.Contains(str, "k"),
+	}
+
+	moves := game.GetMoves()
+	nodes := 0
+
+	if depth == 1 {
+		nodes = len(moves)
+	} else {
+		for _, move := range moves {
+			game.MakeMove(move)
+			nodes += game.PerftWithTable(depth-1, table)
+			game.UndoMove()
 		}
 	}
 
-	return Castling{}
+	if table != nil {
+		table.Store(tt.Entry[Move]{Hash: game.hash, Depth: depth, Bound: tt.Exact, Score: nodes})
+	}
+	return nodes
 }
@@ -164,7 +164,7 @@ func TestMakeMove(t *testing.T) {
 	})
 }
 
-func TestCreateMoveAlgebra(t *testing.T) {
+func TestParseSAN(t *testing.T) {
 	t.Run("Starting Position", func(t *testing.T) {
 		type testEntry struct {
 			from, to string
@@ -193,7 +193,10 @@ func TestCreateMoveAlgebra(t *testing.T) {
 		for _, test := range data {
 			t.Run(test.move, func(t *testing.T) {
 				start := getStartGame()
-				move := start.CreateMoveAlgebra(test.move)
+				move, err := start.ParseSAN(test.move)
+				if err != nil {
+					t.Fatal(err)
+				}
 
 				if move.From.GetAlgebra() != test.from {
 					t.Errorf("expected from to be %v, got %v", test.from, move.From.GetAlgebra())
@@ -213,7 +216,10 @@ func TestCreateMoveAlgebra(t *testing.T) {
 				t.Error(err)
 			}
 
-			move := board.CreateMoveAlgebra("dxe6")
+			move, err := board.ParseSAN("dxe6")
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if move.From.GetAlgebra() != "d5" {
 				t.Errorf("expected from to be d5, got %v", move.From.GetAlgebra())
@@ -223,9 +229,172 @@ func TestCreateMoveAlgebra(t *testing.T) {
 				t.Errorf("expected to to be e6, got %v", move.To.GetAlgebra())
 			}
 		})
+
+		t.Run("Under-Promotion", func(t *testing.T) {
+			board, err := FromFEN("8/3P4/8/8/8/8/8/k1K5 w - - 0 1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			move, err := board.ParseSAN("d8=N+")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if move.Promotion() != White|Knight {
+				t.Errorf("expected promotion to %v, got %v", Piece(White|Knight), move.Promotion())
+			}
+		})
+	})
+
+	t.Run("Castling", func(t *testing.T) {
+		for _, san := range []string{"O-O", "0-0", "O-O-O", "0-0-0"} {
+			t.Run(san, func(t *testing.T) {
+				board, err := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				move, err := board.ParseSAN(san)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !move.IsCastle() {
+					t.Errorf("expected a castle, got %v", move)
+				}
+			})
+		}
+	})
+
+	t.Run("Disambiguation", func(t *testing.T) {
+		board, err := FromFEN("k7/8/8/8/8/5N2/8/KN3N2 w - - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := map[string]string{
+			"Nbd2":  "b1",
+			"N3d2":  "f3",
+			"Nf1d2": "f1",
+		}
+
+		for san, from := range tests {
+			t.Run(san, func(t *testing.T) {
+				move, err := board.ParseSAN(san)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if move.From.GetAlgebra() != from {
+					t.Errorf("expected from to be %v, got %v", from, move.From.GetAlgebra())
+				}
+			})
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := map[string]struct {
+			fen, san string
+		}{
+			"Empty":                   {fen: START_POSITION, san: ""},
+			"Unknown Piece":           {fen: START_POSITION, san: "Xe4"},
+			"Missing Target":          {fen: START_POSITION, san: "N"},
+			"Illegal":                 {fen: START_POSITION, san: "e5"},
+			"Blocked Castle":          {fen: START_POSITION, san: "O-O"},
+			"Ambiguous":               {fen: "k7/8/8/8/8/5N2/8/KN3N2 w - - 0 1", san: "Nd2"},
+			"Promotion To King":       {fen: "8/3P4/8/8/8/8/8/k1K5 w - - 0 1", san: "d8=K"},
+			"Promotion Too Early":     {fen: START_POSITION, san: "e4=Q"},
+			"Non-Pawn Promotion":      {fen: "8/3P4/8/8/8/8/8/k1K1R3 w - - 0 1", san: "Re8=Q"},
+			"Into Check":              {fen: "4r2k/8/8/1N6/8/8/4N3/4K3 w - - 0 1", san: "Ned4"},
+			"Capture Of Empty Square": {fen: "k7/8/8/8/8/5N2/8/KN3N2 w - - 0 1", san: "Nf1xh2"},
+			"Malformed Promotion":     {fen: "8/3P4/8/8/8/8/8/k1K5 w - - 0 1", san: "d8=QQ"},
+			"Bare Promotion":          {fen: "8/3P4/8/8/8/8/8/k1K5 w - - 0 1", san: "=Q"},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				board, err := FromFEN(test.fen)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if move, err := board.ParseSAN(test.san); err == nil {
+					t.Errorf("expected error for %q, got %v", test.san, move)
+				}
+			})
+		}
 	})
 }
 
+func TestClocks(t *testing.T) {
+	game, err := FromFEN("r3k3/8/8/8/8/8/4P3/R3K2R w KQq - 7 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moves := []struct {
+		from, to  string
+		halfMoves int
+		fullMoves int
+	}{
+		{from: "a1", to: "a2", halfMoves: 8, fullMoves: 20},
+		{from: "a8", to: "a7", halfMoves: 9, fullMoves: 21},
+		{from: "e1", to: "h1", halfMoves: 10, fullMoves: 21},
+		{from: "a7", to: "a2", halfMoves: 0, fullMoves: 22},
+		{from: "e2", to: "e3", halfMoves: 0, fullMoves: 22},
+		{from: "e8", to: "d8", halfMoves: 1, fullMoves: 23},
+	}
+
+	fens := []string{game.ToFEN()}
+	for _, move := range moves {
+		game.MakeMove(game.CreateMoveStr(move.from, move.to))
+
+		if game.HalfMoves != move.halfMoves || game.FullMoves != move.fullMoves {
+			t.Errorf("after %v%v expected clocks %d %d, got %d %d", move.from, move.to,
+				move.halfMoves, move.fullMoves, game.HalfMoves, game.FullMoves)
+		}
+
+		fens = append(fens, game.ToFEN())
+	}
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		game.UndoMove()
+
+		if game.ToFEN() != fens[i] {
+			t.Errorf("expected undo to restore %v, got %v", fens[i], game.ToFEN())
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	game, err := FromFEN("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.MakeMove(game.CreateMoveStr("e5", "f6"))
+
+	clone := game.Clone()
+	if clone.ToFEN() != game.ToFEN() || clone.Hash() != game.Hash() {
+		t.Fatalf("expected clone to match %v, got %v", game.ToFEN(), clone.ToFEN())
+	}
+
+	fen := game.ToFEN()
+	clone.MakeMove(clone.CreateMoveStr("g8", "f6"))
+	clone.UndoMove()
+	clone.UndoMove()
+	clone.MakeMove(clone.CreateMoveStr("a2", "a4"))
+
+	if game.ToFEN() != fen || len(game.Moves) != 1 {
+		t.Errorf("changing the clone changed the original, expected %v, got %v", fen, game.ToFEN())
+	}
+
+	game.UndoMove()
+	if game.ToFEN() != "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3" {
+		t.Errorf("expected the original to undo independently, got %v", game.ToFEN())
+	}
+}
+
 func TestUndoMove(t *testing.T) {
 	t.Run("ChangesTurn", func(t *testing.T) {
 		start := getStartGame()
@@ -341,6 +510,32 @@ func TestUndoMove(t *testing.T) {
 				}
 			})
 
+			t.Run("OnNestedUndo", func(t *testing.T) {
+				board, err := FromFEN("rnbqkbnr/ppppp1pp/8/4Pp2/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2")
+
+				if err != nil {
+					t.Error(err)
+				}
+
+				board.MakeMove(board.CreateMoveStr("d7", "d5"))
+				// En Passant Available
+				board.MakeMove(board.CreateMoveStr("a2", "a3"))
+				board.MakeMove(board.CreateMoveStr("a7", "a6"))
+
+				board.UndoMove()
+				board.UndoMove()
+				// En Passant Re-Available
+
+				expected := CreateCoordAlgebra("d6")
+				if board.EnPassant == nil || *board.EnPassant != expected {
+					if board.EnPassant == nil {
+						t.Errorf("board failed to re-mark en passant, expected %v, got %v", expected, nil)
+					} else {
+						t.Errorf("board failed to re-mark en passant, expected %v, got %v", expected, *board.EnPassant)
+					}
+				}
+			})
+
 			t.Run("OnTwoUndo", func(t *testing.T) {
 				board, err := FromFEN("rnbqkbnr/ppppp1pp/8/4Pp2/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2")
 
@@ -3,7 +3,10 @@ package board
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 func GeneratePieceString(arr [8][8]Piece) string {
@@ -41,22 +44,72 @@ func generatePieceRow(row [8]Piece) string {
 	return sb.String()
 }
 
+// Errors wrapped by FENError, for use with errors.Is.
+// FromFEN checks the syntax of each field, ValidateFEN
+// additionally checks that the position is legal.
+var (
+	ErrRecordCount          = errors.New("expected 6 records")
+	ErrRankCount            = errors.New("expected 8 ranks")
+	ErrRankLength           = errors.New("rank does not have 8 squares")
+	ErrBadPiece             = errors.New("invalid piece")
+	ErrBadActiveColor       = errors.New("invalid active color")
+	ErrBadCastling          = errors.New("invalid castling rights")
+	ErrBadEnPassant         = errors.New("invalid en passant square")
+	ErrBadClock             = errors.New("invalid move clock")
+	ErrKingCount            = errors.New("each side must have exactly one king")
+	ErrPawnOnBackRank       = errors.New("pawn on the first or last rank")
+	ErrSideNotToMoveInCheck = errors.New("side not to move is in check")
+)
+
+type FENError struct {
+	// 1-based index of the space separated field, 0 if the record count is wrong
+	Field int
+	// 1-based index of the offending character within the field,
+	// 0 if the field as a whole is at fault
+	Column int
+	Err    error
+}
+
+func (err *FENError) Error() string {
+	switch {
+	case err.Field == 0:
+		return fmt.Sprintf("invalid fen: %v", err.Err)
+	case err.Column == 0:
+		return fmt.Sprintf("invalid fen field %d: %v", err.Field, err.Err)
+	}
+
+	return fmt.Sprintf("invalid fen field %d, column %d: %v", err.Field, err.Column, err.Err)
+}
+
+func (err *FENError) Unwrap() error {
+	return err.Err
+}
+
+// Parses the piece placement field of a FEN string, returning a *FENError
+// if there are not 8 ranks of 8 squares.
 func GenerateBoard(str string) (*[8][8]Piece, error) {
 	board := &[8][8]Piece{}
 	ranks := strings.Split(str, "/")
 
 	if len(ranks) != 8 {
-		return board, fmt.Errorf("invalid number of rows (%d)", len(ranks))
+		return board, &FENError{Field: 1, Err: fmt.Errorf("%w, got %d", ErrRankCount, len(ranks))}
 	}
 
+	offset := 0
 	for rank, row := range ranks {
 		result, err := generateBoardRow(row)
 
+		var fenErr *FENError
+		if errors.As(err, &fenErr) {
+			fenErr.Column += offset
+		}
+
 		if err != nil {
 			return board, err
 		}
 
 		board[7-rank] = *result
+		offset += len(row) + 1
 	}
 
 	return board, nil
@@ -64,31 +117,260 @@ func GenerateBoard(str string) (*[8][8]Piece, error) {
 
 func generateBoardRow(str string) (*[8]Piece, error) {
 	row := &[8]Piece{}
-	if len(str) == 0 {
-		return row, nil
-	}
-
 	column := 0
+	digit := false
 
-	for _, c := range str {
+	for i, c := range str {
 		if column >= len(row) {
-			return row, errors.New("out of bounds column")
+			return row, &FENError{Field: 1, Column: i + 1, Err: ErrRankLength}
 		}
 
 		if c >= '1' && c <= '8' {
+			// Empty squares are counted by a single digit, so "44" is not "8"
+			if digit {
+				return row, &FENError{Field: 1, Column: i + 1, Err: fmt.Errorf("%w, got consecutive digits", ErrRankLength)}
+			}
 			column += int(c - '0')
+			digit = true
 			continue
 		}
+		digit = false
 
 		piece, err := GetPiece(c)
 
 		if err != nil {
-			return row, err
+			return row, &FENError{Field: 1, Column: i + 1, Err: fmt.Errorf("%w %q", ErrBadPiece, c)}
 		}
 
 		row[column] = piece
 		column++
 	}
 
+	if column != len(row) {
+		return row, &FENError{Field: 1, Column: len(str) + 1, Err: fmt.Errorf("%w, got %d", ErrRankLength, column)}
+	}
+
 	return row, nil
 }
+
+// Parses the castling field, which must be "-" or
+// some of KQkq with no letter repeated.
+func parseCastling(str string) (Castling, Castling, error) {
+	white, black := Castling{}, Castling{}
+	if str == "-" {
+		return white, black, nil
+	}
+
+	if str == "" {
+		return white, black, &FENError{Field: 3, Err: ErrBadCastling}
+	}
+
+	for i, c := range str {
+		var right *bool
+		switch c {
+		case 'K':
+			right = &white.KingSide
+		case 'Q':
+			right = &white.QueenSide
+		case 'k':
+			right = &black.KingSide
+		case 'q':
+			right = &black.QueenSide
+		}
+
+		if right == nil || *right {
+			return white, black, &FENError{Field: 3, Column: i + 1, Err: fmt.Errorf("%w %q", ErrBadCastling, c)}
+		}
+
+		*right = true
+	}
+
+	return white, black, nil
+}
+
+// Parses the castling field like parseCastling, also accepting X-FEN
+// and Shredder-FEN rights for Chess960. Shredder-FEN gives the rook's file
+// (A-H for white, a-h for black), while X-FEN's KQkq refer to the outermost
+// rook on that side of the king. The game is marked as Chess960 if any
+// right is for a king or rook off its standard square.
+// When strict, a Shredder-FEN file must hold one of that side's rooks.
+func (game *Game) parseXFENCastling(str string, strict bool) error {
+	if str == "-" {
+		return nil
+	}
+
+	if str == "" {
+		return &FENError{Field: 3, Err: ErrBadCastling}
+	}
+
+	for i, c := range str {
+		castling, color, row, letter := &game.WhiteCastling, White, 0, c
+		if unicode.IsLower(c) {
+			castling, color, row, letter = &game.BlackCastling, Black, 7, unicode.ToUpper(c)
+		}
+
+		invalid := &FENError{Field: 3, Column: i + 1, Err: fmt.Errorf("%w %q", ErrBadCastling, c)}
+		kingCol := slices.Index(game.Board[row][:], King|color)
+
+		rookCol := -1
+		switch {
+		case letter == 'K' || letter == 'Q':
+			// The outermost rook on that side of the king, else the corner
+			step := 1
+			rookCol = 0
+			if letter == 'K' {
+				rookCol, step = 7, -1
+			}
+
+			for col := rookCol; kingCol != -1 && col != kingCol; col += step {
+				if game.Board[row][col] == Rook|color {
+					rookCol = col
+					break
+				}
+			}
+		case letter >= 'A' && letter <= 'H' && kingCol != -1:
+			rookCol = int(letter - 'A')
+			if strict && game.Board[row][rookCol] != Rook|color {
+				return invalid
+			}
+		}
+
+		if rookCol == -1 || rookCol == kingCol {
+			return invalid
+		}
+
+		kingSide := letter == 'K' || (letter != 'Q' && rookCol > kingCol)
+		right, side := &castling.QueenSide, 0
+		if kingSide {
+			right, side = &castling.KingSide, 1
+		}
+
+		if *right {
+			return invalid
+		}
+
+		*right = true
+		game.rookFiles[colorIndex(color)][side] = rookCol
+		if (kingCol != -1 && kingCol != 4) || rookCol != side*7 {
+			game.Chess960 = true
+		}
+	}
+
+	return nil
+}
+
+// Parses the en passant field, which must be "-" or the square behind a pawn
+// the opponent just double pushed: on the sixth rank when white is to move,
+// or the third when black is
+func parseEnPassant(str string, active Piece) (*Coordinate, error) {
+	if str == "-" {
+		return nil, nil
+	}
+
+	rank := byte('6')
+	if active == Black {
+		rank = '3'
+	}
+
+	if len(str) != 2 || !isSquare(str) || str[1] != rank {
+		return nil, &FENError{Field: 4, Err: fmt.Errorf("%w %q", ErrBadEnPassant, str)}
+	}
+
+	coord := CreateCoordAlgebra(str)
+	return &coord, nil
+}
+
+func parseClock(str string, field int) (int, error) {
+	clock, err := strconv.Atoi(str)
+	if err != nil || clock < 0 {
+		return 0, &FENError{Field: field, Err: fmt.Errorf("%w %q", ErrBadClock, str)}
+	}
+
+	return clock, nil
+}
+
+// Parses the FEN like FromFEN, and also rejects positions that
+// cannot arise in a game: a side without exactly one king, pawns on
+// the back ranks, castling rights without the king and rook at home,
+// an en passant square no pawn could have skipped, or the side
+// that just moved being left in check.
+// All errors are of type *FENError.
+func ValidateFEN(str string) error {
+	game, err := FromFEN(str)
+	if err != nil {
+		return err
+	}
+
+	records := strings.Split(strings.TrimSpace(str), " ")
+	bits := game.bitboards()
+
+	for color, name := range []string{"white", "black"} {
+		if kings := bitsSet(bits.pieces[color][typeIndex(King)]); kings != 1 {
+			return &FENError{Field: 1, Err: fmt.Errorf("%w, %s has %d", ErrKingCount, name, kings)}
+		}
+	}
+
+	ranks := strings.Split(records[0], "/")
+	if i := strings.IndexAny(ranks[0], "Pp"); i != -1 {
+		return &FENError{Field: 1, Column: i + 1, Err: ErrPawnOnBackRank}
+	}
+
+	if i := strings.IndexAny(ranks[7], "Pp"); i != -1 {
+		return &FENError{Field: 1, Column: len(records[0]) - len(ranks[7]) + i + 1, Err: ErrPawnOnBackRank}
+	}
+
+	if records[2] != "-" {
+		for i, c := range records[2] {
+			color, row, letter := White, 0, c
+			if unicode.IsLower(c) {
+				color, row, letter = Black, 7, unicode.ToUpper(c)
+			}
+
+			// Outside of Chess960 the king must be on the e-file,
+			// and the rook on the side the right is for
+			kingCol := slices.Index(game.Board[row][:], King|color)
+			if !game.Chess960 && kingCol != 4 {
+				kingCol = -1
+			}
+
+			rookCol := int(letter - 'A')
+			if letter == 'K' || letter == 'Q' {
+				rookCol = game.rookFile(color, letter == 'K')
+			}
+
+			kingSide := letter == 'K' || (letter != 'Q' && rookCol > kingCol)
+			if kingCol == -1 || game.Board[row][rookCol] != Rook|color || (rookCol > kingCol) != kingSide {
+				return &FENError{Field: 3, Column: i + 1, Err: fmt.Errorf("%w %q, king or rook has moved", ErrBadCastling, c)}
+			}
+		}
+	}
+
+	if game.EnPassant != nil {
+		// The pawn that skipped the square must be in front of it,
+		// with the square and the one it started on empty
+		row, col := game.EnPassant.GetCoords()
+		expectedRow, forward, pawn := byte(5), -1, Black|Pawn
+		if game.Active == Black {
+			expectedRow, forward, pawn = 2, 1, White|Pawn
+		}
+
+		if row != expectedRow ||
+			game.Board[int(row)+forward][col] != pawn ||
+			game.Board[row][col] != 0 ||
+			game.Board[int(row)-forward][col] != 0 {
+			return &FENError{Field: 4, Err: fmt.Errorf("%w %q, no pawn could have skipped it", ErrBadEnPassant, records[3])}
+		}
+	}
+
+	opponent := *game
+	opponent.Active = (^game.Active).GetColor()
+	if opponent.InCheck() {
+		return &FENError{Field: 2, Err: ErrSideNotToMoveInCheck}
+	}
+
+	if game.FullMoves < 1 {
+		return &FENError{Field: 6, Err: fmt.Errorf("%w %q, full moves start at 1", ErrBadClock, records[5])}
+	}
+
+	return nil
+}
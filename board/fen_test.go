@@ -1,6 +1,9 @@
 package board
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestGenerateBoard(t *testing.T) {
 	tests := map[string]struct {
@@ -164,3 +167,165 @@ func TestGeneratePieceString(t *testing.T) {
 		})
 	}
 }
+
+func TestFromFENErrors(t *testing.T) {
+	tests := map[string]struct {
+		fen    string
+		err    error
+		field  int
+		column int
+	}{
+		"Missing Records":    {"8/8/8/8/8/8/8/K6k w - -", ErrRecordCount, 0, 0},
+		"Missing Rank":       {"8/8/8/8/8/8/K6k w - - 0 1", ErrRankCount, 1, 0},
+		"Short Rank":         {"8/8/8/8/8/8/8/K5k w - - 0 1", ErrRankLength, 1, 18},
+		"Long Rank":          {"8/8/8/8/8/8/8/K6kK w - - 0 1", ErrRankLength, 1, 18},
+		"Overflowing Rank":   {"8/8/8/8/8/8/8/K7k w - - 0 1", ErrRankLength, 1, 17},
+		"Empty Rank":         {"8/8/8//8/8/8/K6k w - - 0 1", ErrRankLength, 1, 7},
+		"Consecutive Digits": {"44/8/8/8/8/8/8/K6k w - - 0 1", ErrRankLength, 1, 2},
+		"Bad Piece":          {"8/8/8/8/8/8/8/K5xk w - - 0 1", ErrBadPiece, 1, 17},
+		"Bad Active Color":   {"8/8/8/8/8/8/8/K6k white - - 0 1", ErrBadActiveColor, 2, 0},
+		"Junk Castling":      {"8/8/8/8/8/8/8/K6k w KQx - 0 1", ErrBadCastling, 3, 3},
+		"Repeated Castling":  {"8/8/8/8/8/8/8/K6k w KK - 0 1", ErrBadCastling, 3, 2},
+		"Bad En Passant":     {"8/8/8/8/8/8/8/K6k w - e9 0 1", ErrBadEnPassant, 4, 0},
+		"En Passant Rank":    {"8/8/8/8/8/8/8/K6k w - e4 0 1", ErrBadEnPassant, 4, 0},
+		"Short En Passant":   {"8/8/8/8/8/8/8/K6k w - e 0 1", ErrBadEnPassant, 4, 0},
+		"White En Passant":   {"4k3/8/8/8/8/8/3P4/4K3 w - e3 0 1", ErrBadEnPassant, 4, 0},
+		"Black En Passant":   {"4k3/3p4/8/8/8/8/8/4K3 b - e6 0 1", ErrBadEnPassant, 4, 0},
+		"Bad Half Moves":     {"8/8/8/8/8/8/8/K6k w - - x 1", ErrBadClock, 5, 0},
+		"Negative Clock":     {"8/8/8/8/8/8/8/K6k w - - 0 -1", ErrBadClock, 6, 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FromFEN(test.fen)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+
+			var fenErr *FENError
+			if !errors.As(err, &fenErr) {
+				t.Fatalf("expected *FENError, got %v", err)
+			}
+
+			if fenErr.Field != test.field || fenErr.Column != test.column {
+				t.Errorf("expected field %d, column %d, got %v", test.field, test.column, err)
+			}
+		})
+	}
+}
+
+// FromFEN leaves checking the pawn to ValidateFEN,
+// but there must be no en passant capture of an empty square
+func TestEnPassantWithoutPawn(t *testing.T) {
+	game, err := FromFEN("4k3/8/8/3P4/8/8/8/4K3 w - e6 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, move := range game.GetMoves() {
+		if move.IsEnPassant() {
+			t.Errorf("unexpected en passant %v", move.UCI())
+		}
+	}
+}
+
+func TestValidateFEN(t *testing.T) {
+	tests := map[string]struct {
+		fen    string
+		err    error
+		field  int
+		column int
+	}{
+		"Start Position":   {START_POSITION, nil, 0, 0},
+		"Shredder":         {"rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1", nil, 0, 0},
+		"Shredder No Rook": {"rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w GAha - 0 1", ErrBadCastling, 3, 1},
+		"Shredder Side":    {"1k5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HA - 0 1", nil, 0, 0},
+		"En Passant":       {"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", nil, 0, 0},
+		"No Black King":    {"8/8/8/8/8/8/8/K7 w - - 0 1", ErrKingCount, 1, 0},
+		"Two White Kings":  {"k7/8/8/8/8/8/8/K6K w - - 0 1", ErrKingCount, 1, 0},
+		"Pawn On Rank 8":   {"k2P4/8/8/8/8/8/8/K7 w - - 0 1", ErrPawnOnBackRank, 1, 3},
+		"Pawn On Rank 1":   {"k7/8/8/8/8/8/8/K3p3 w - - 0 1", ErrPawnOnBackRank, 1, 18},
+		"Castling Moved":   {"r3k2r/8/8/8/8/8/8/R3K1R1 w KQkq - 0 1", ErrBadCastling, 3, 1},
+		"Castling No Rook": {"r3k3/8/8/8/8/8/8/R3K2R w KQkq - 0 1", ErrBadCastling, 3, 3},
+		"En Passant Side":  {"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR b KQkq f6 0 3", ErrBadEnPassant, 4, 0},
+		"En Passant Pawn":  {"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", ErrBadEnPassant, 4, 0},
+		"En Passant Empty": {"4k3/8/8/3P4/8/8/8/4K3 w - e6 0 1", ErrBadEnPassant, 4, 0},
+		"Not To Move":      {"k7/8/8/8/8/8/8/K6r w - - 0 1", nil, 0, 0},
+		"Side Not To Move": {"k7/8/8/8/8/8/8/K6r b - - 0 1", ErrSideNotToMoveInCheck, 2, 0},
+		"Zero Full Moves":  {"k7/8/8/8/8/8/8/K7 w - - 0 0", ErrBadClock, 6, 0},
+		"Syntax":           {"k7/8/8/8/8/8/8/K7 w - e4 0 1", ErrBadEnPassant, 4, 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateFEN(test.fen)
+			if test.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+
+			var fenErr *FENError
+			if !errors.As(err, &fenErr) {
+				t.Fatalf("expected *FENError, got %v", err)
+			}
+
+			if fenErr.Field != test.field || fenErr.Column != test.column {
+				t.Errorf("expected field %d, column %d, got %v", test.field, test.column, err)
+			}
+		})
+	}
+}
+
+func TestFromFENLenient(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected string
+	}{
+		"Full":           {START_POSITION, START_POSITION},
+		"No Clocks":      {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", START_POSITION},
+		"No Full Moves":  {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 3", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 3 1"},
+		"Extra Spaces":   {"  rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR   w\tKQkq  -  0 1 ", START_POSITION},
+		"Shredder":       {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1", START_POSITION},
+		"Partial Rights": {"r3k2r/8/8/8/8/8/8/R3K2R b Hh -", "r3k2r/8/8/8/8/8/8/R3K2R b Kk - 0 1"},
+		"Chess960":       {"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFENLenient(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if game.ToFEN() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, game.ToFEN())
+			}
+		})
+	}
+
+	errorTests := map[string]struct {
+		fen string
+		err error
+	}{
+		"Too Few Records":  {"8/8/8/8/8/8/8/K6k w -", ErrRecordCount},
+		"Too Many Records": {"8/8/8/8/8/8/8/K6k w - - 0 1 extra", ErrRecordCount},
+		"Rook On King":     {"4k3/8/8/8/8/8/8/4K3 w E - 0 1", ErrBadCastling},
+		"No King":          {"4k3/8/8/8/8/8/8/R7 w A - 0 1", ErrBadCastling},
+		"Repeated Side":    {"4k3/8/8/8/8/8/8/R3K2R w KH - 0 1", ErrBadCastling},
+		"Not A File":       {"4k3/8/8/8/8/8/8/R3K2R w KZ - 0 1", ErrBadCastling},
+	}
+
+	for name, test := range errorTests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := FromFENLenient(test.fen); !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package board
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 )
 
@@ -14,19 +13,73 @@ type Move struct {
 	isEnPassant bool
 }
 
+// Castling is represented as the king capturing its own rook,
+// which also covers Chess960 castles where the king moves one square or none.
 func (move Move) IsCastle() bool {
-	if move.Piece.GetType() != King {
-		return false
-	}
+	return move.Piece.GetType() == King && move.Capture == Rook|move.Piece.GetColor()
+}
 
+// Whether the move castles with the rook towards the h-file
+func (move Move) castlesKingSide() bool {
 	_, fromCol := move.From.GetCoords()
 	_, toCol := move.To.GetCoords()
+	return toCol > fromCol
+}
+
+// The squares the king and rook land on when castling,
+// which are the same as in standard chess for Chess960
+func (move Move) castleSquares() (king, rook Coordinate) {
+	row, _ := move.To.GetCoords()
+	if move.castlesKingSide() {
+		return CreateCoordByte(row, 6), CreateCoordByte(row, 5)
+	}
+
+	return CreateCoordByte(row, 2), CreateCoordByte(row, 3)
+}
 
-	if fromCol > toCol {
-		toCol, fromCol = fromCol, toCol
+// Returns the (colored) piece the pawn promotes to,
+// or 0 if the move is not a promotion.
+// A zero promotionTo on a promoting move defaults to a Queen.
+func (move Move) Promotion() Piece {
+	if move.Piece.GetType() != Pawn {
+		return 0
 	}
 
-	return toCol-fromCol > 1
+	row, _ := move.To.GetCoords()
+	if row != 0 && row != 7 {
+		return 0
+	}
+
+	if move.promotionTo == 0 {
+		return Queen | move.Piece.GetColor()
+	}
+
+	return move.promotionTo.GetType() | move.Piece.GetColor()
+}
+
+func (move Move) IsPromotion() bool {
+	return move.Promotion() != 0
+}
+
+func (move Move) IsEnPassant() bool {
+	return move.isEnPassant
+}
+
+// Whether the move captures an enemy piece, including en passant.
+// Castling is not a capture, despite being encoded as capturing a rook.
+func (move Move) IsCapture() bool {
+	return move.isEnPassant || (move.Capture != 0 && !move.IsCastle())
+}
+
+// Creates a pawn move that promotes to piece, which must be colored.
+// Capture is left unset, MakeMove fills it in from the board.
+func NewPromotion(from, to Coordinate, piece Piece) Move {
+	return Move{
+		From:        from,
+		To:          to,
+		Piece:       Pawn | piece.GetColor(),
+		promotionTo: piece,
+	}
 }
 
 func (move Move) String() string {
@@ -45,43 +98,118 @@ func (move Move) String() string {
 	return sb.String()
 }
 
+// Formats the move in UCI long algebraic notation (e.g. e2e4, e7e8q),
+// with castling written as the king's move to its destination (e1g1).
+func (move Move) UCI() string {
+	return move.formatUCI(false)
+}
+
+// Formats the move like UCI, but with castling written as
+// the king capturing its own rook (e1h1), as expected with UCI_Chess960.
+func (move Move) UCIChess960() string {
+	return move.formatUCI(true)
+}
+
+func (move Move) formatUCI(kingTakesRook bool) string {
+	var sb strings.Builder
+	sb.WriteString(move.From.GetAlgebra())
+
+	to := move.To
+	if move.IsCastle() && !kingTakesRook {
+		to, _ = move.castleSquares()
+	}
+	sb.WriteString(to.GetAlgebra())
+
+	if promotion := move.Promotion(); promotion != 0 {
+		sb.WriteRune((promotion.GetType() | Black).GetRune())
+	}
+
+	return sb.String()
+}
+
+// Formats the move in Standard Algebraic Notation,
+// game must be the position the move is played from.
 func (move Move) GetAlgebra(game *Game) string {
 	var result strings.Builder
 	_, fromCol := move.From.GetCoords()
+	_, toCol := move.To.GetCoords()
 
 	switch move.Piece.GetType() {
 	case Pawn:
-		result.WriteRune(rune(int(fromCol) + 'a'))
+		if move.isEnPassant || fromCol != toCol {
+			result.WriteRune(rune(int(fromCol) + 'a'))
+			result.WriteRune('x')
+		}
 	case King:
 		if move.IsCastle() {
-			if fromCol == 4 {
+			if move.castlesKingSide() {
 				result.WriteString("O-O")
 			} else {
 				result.WriteString("O-O-O")
 			}
-		} else {
-			result.WriteRune('K')
+			break
+		}
+		fallthrough
+	default:
+		result.WriteRune(move.Piece.GetType().GetRune())
+		result.WriteString(move.disambiguate(game))
+
+		if move.Capture != 0 {
+			result.WriteRune('x')
 		}
-	case Queen:
-		result.WriteRune('Q')
-	case Rook:
-		result.WriteRune('R')
-	case Bishop:
-		result.WriteRune('B')
-	case Knight:
-		result.WriteRune('N')
 	}
 
-	if move.Capture != 0 {
-		result.WriteString("x")
+	if !move.IsCastle() {
+		result.WriteString(move.To.GetAlgebra())
+	}
+
+	if promotion := move.Promotion(); promotion != 0 {
+		result.WriteRune('=')
+		result.WriteRune(promotion.GetType().GetRune())
 	}
 
-	result.WriteString(move.To.GetAlgebra())
+	game.MakeMove(move)
+	if game.InCheck() {
+		if len(game.GetMoves()) == 0 {
+			result.WriteRune('#')
+		} else {
+			result.WriteRune('+')
+		}
+	}
+	game.UndoMove()
 
-	// TODO: Checks, Mates
 	return result.String()
 }
 
+// Returns the file, rank or square needed to tell this move apart
+// from other legal moves of the same piece type to the same square
+func (move Move) disambiguate(game *Game) string {
+	fromRow, fromCol := move.From.GetCoords()
+	sameFile, sameRank, ambiguous := false, false, false
+
+	for _, other := range game.GetMoves() {
+		if other.From == move.From || other.To != move.To || other.Piece != move.Piece {
+			continue
+		}
+
+		ambiguous = true
+		otherRow, otherCol := other.From.GetCoords()
+		sameFile = sameFile || otherCol == fromCol
+		sameRank = sameRank || otherRow == fromRow
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(rune('a' + fromCol))
+	case !sameRank:
+		return string(rune('1' + fromRow))
+	default:
+		return move.From.GetAlgebra()
+	}
+}
+
 func (board Game) CreateMove(from Coordinate, to Coordinate) Move {
 	result := Move{
 		From:    from,
@@ -100,473 +228,456 @@ func (board Game) CreateMoveStr(from string, to string) Move {
 	)
 }
 
-func (board Game) CreateMoveAlgebra(algebra string) Move {
-	algebra = regexp.MustCompile("[x+#]").ReplaceAllString(algebra, "")
-	if len(algebra) == 2 {
-		// Pawn move
-		pawnDir := -1
-		if board.Active == Black {
-			pawnDir = 1
-		}
-		ourPawn := board.Active | Pawn
+// Parses a move in Standard Algebraic Notation, such as "Nbd7", "exd6",
+// "e8=N" or "O-O", returning an error if the move is malformed,
+// illegal or ambiguous in the current position.
+func (game Game) ParseSAN(san string) (Move, error) {
+	str := strings.TrimRight(san, "+#!?")
+	if str == "" {
+		return Move{}, fmt.Errorf("empty move")
+	}
 
-		target := CreateCoordAlgebra(algebra)
-		if board.Get(target.Add(pawnDir, 0)) == ourPawn {
-			return board.CreateMove(target.Add(pawnDir, 0), target)
-		} else if board.Get(target.Add(pawnDir*2, 0)) == ourPawn {
-			return board.CreateMove(target.Add(pawnDir*2, 0), target)
-		} else {
-			panic(fmt.Errorf("invalid move: %v", algebra))
+	if castle := strings.ReplaceAll(str, "0", "O"); castle == "O-O" || castle == "O-O-O" {
+		for _, move := range game.GetMoves() {
+			if move.IsCastle() && move.castlesKingSide() == (castle == "O-O") {
+				return move, nil
+			}
 		}
+
+		return Move{}, fmt.Errorf("illegal move %s: cannot castle", san)
 	}
 
-	if algebra[0] == 'O' || algebra[0] == '0' {
-		castleRow := 0
-		if board.Active == Black {
-			castleRow = 7
+	var promotion Piece
+	if index := strings.IndexRune(str, '='); index != -1 {
+		if index != len(str)-2 {
+			return Move{}, fmt.Errorf("invalid move %s: malformed promotion", san)
 		}
 
-		fromCol := 4
-		toCol := 0
+		piece, err := getPromotionPiece(str[index+1])
+		if err != nil {
+			return Move{}, fmt.Errorf("invalid move %s: %w", san, err)
+		}
+		promotion = piece
+		str = str[:index]
+		if str == "" {
+			return Move{}, fmt.Errorf("invalid move %s: missing target square", san)
+		}
+	}
 
-		if len(algebra) == 3 {
-			toCol = 7
+	piece := Pawn
+	if first := str[0]; first >= 'A' && first <= 'Z' {
+		p, err := GetPiece(rune(first))
+		if err != nil || p.GetType() == Pawn {
+			return Move{}, fmt.Errorf("invalid move %s: unknown piece %c", san, first)
 		}
+		piece = p.GetType()
+		str = str[1:]
+	}
 
-		return board.CreateMove(
-			CreateCoordInt(castleRow, fromCol),
-			CreateCoordInt(castleRow, toCol),
-		)
-	}
-
-	if len(algebra) == 5 {
-		// Double disambiguation, we know exactly from -> to
-		return board.CreateMoveStr(algebra[1:3], algebra[3:])
-	}
-
-	piece := King
-	switch strings.ToLower(algebra)[0] {
-	case 'n':
-		piece = Knight
-	case 'b':
-		piece = Bishop
-	case 'r':
-		piece = Rook
-	case 'q':
-		piece = Queen
-	case 'k':
-		piece = King
-	default:
-		piece = Pawn
+	if len(str) < 2 || !isSquare(str[len(str)-2:]) {
+		return Move{}, fmt.Errorf("invalid move %s: missing target square", san)
 	}
 
-	return board.createMoveFromTarget(piece, algebra[1:])
-}
+	target := CreateCoordAlgebra(str[len(str)-2:])
+	disambiguation := str[:len(str)-2]
+	capture := strings.HasSuffix(disambiguation, "x")
+	disambiguation = strings.TrimSuffix(disambiguation, "x")
 
-func (board Game) createMoveFromTarget(piece Piece, algebra string) Move {
-	if len(algebra) == 3 {
-		target := CreateCoordAlgebra(algebra[1:])
-		if algebra[0] >= 'a' && algebra[0] <= 'h' {
-			return board.createMoveFromDisambiguatedCol(piece, algebra[0]-'a', target)
-		}
-		if algebra[0] >= '1' && algebra[0] <= '8' {
-			return board.createMoveFromDisambiguatedRow(piece, algebra[0]-'1', target)
-		}
-		panic(fmt.Errorf("invalid move: %v", algebra))
+	if len(disambiguation) > 2 || (len(disambiguation) == 2 && !isSquare(disambiguation)) {
+		return Move{}, fmt.Errorf("invalid move %s: malformed disambiguation", san)
+	}
+
+	if promotion != 0 && piece != Pawn {
+		return Move{}, fmt.Errorf("invalid move %s: only pawns can promote", san)
 	}
 
-	target := CreateCoordAlgebra(algebra)
-	source := board.getSourceCoord(piece, func(coord Coordinate) bool {
-		immediateMoves := board.getMovesFor(coord)
-		for _, move := range immediateMoves {
-			if move.To == target {
-				return true
+	if piece == Pawn {
+		if row, _ := target.GetCoords(); row == 0 || row == 7 {
+			if promotion == 0 {
+				promotion = Queen
 			}
+		} else if promotion != 0 {
+			return Move{}, fmt.Errorf("invalid move %s: pawns only promote on the last rank", san)
 		}
+	}
 
-		return false
-	})
+	candidates := []Move{}
+	for _, move := range game.GetMoves() {
+		if move.Piece.GetType() != piece || move.To != target || move.IsCastle() {
+			continue
+		}
 
-	return board.CreateMove(source, CreateCoordAlgebra(algebra))
-}
+		if move.Promotion().GetType() != promotion {
+			continue
+		}
 
-func (board Game) createMoveFromDisambiguatedCol(piece Piece, row byte, target Coordinate) Move {
-	source := board.getSourceCoord(piece, func(coord Coordinate) bool {
-		_, col := coord.GetCoords()
-		return col == row
-	})
+		if capture && !move.IsCapture() {
+			continue
+		}
 
-	return board.CreateMove(source, target)
-}
+		if !matchesDisambiguation(move.From, disambiguation) {
+			continue
+		}
 
-func (board Game) createMoveFromDisambiguatedRow(piece Piece, row byte, target Coordinate) Move {
-	source := board.getSourceCoord(piece, func(coord Coordinate) bool {
-		cRow, _ := coord.GetCoords()
-		return row == cRow
-	})
+		candidates = append(candidates, move)
+	}
 
-	return board.CreateMove(source, target)
+	switch len(candidates) {
+	case 0:
+		return Move{}, fmt.Errorf("illegal move %s: no %c can move to %v", san, piece.GetRune(), target.GetAlgebra())
+	case 1:
+		return candidates[0], nil
+	default:
+		return Move{}, fmt.Errorf("ambiguous move %s: %d pieces can move to %v", san, len(candidates), target.GetAlgebra())
+	}
 }
 
-func (board Game) getSourceCoord(piece Piece, filter func(Coordinate) bool) Coordinate {
-	for row := 0; row < len(board.Board); row++ {
-		for col := 0; col < len(board.Board[row]); col++ {
-			p := board.Board[row][col]
-			if p == 0 || p.GetType() != piece || p.GetColor() != board.Active {
-				continue
-			}
-			if !filter(CreateCoordInt(row, col)) {
-				continue
-			}
-			return CreateCoordInt(row, col)
-		}
+// Parses a move in UCI long algebraic notation, such as "e2e4" or "e7e8q".
+// Castling may be written either as the king's move to its destination (e1g1)
+// or as the king capturing its own rook (e1h1). In Chess960 only the latter
+// is accepted, as the former can be indistinguishable from a king move.
+func (game Game) ParseUCIMove(str string) (Move, error) {
+	if len(str) != 4 && len(str) != 5 {
+		return Move{}, fmt.Errorf("invalid move %s: expected 4 or 5 characters", str)
 	}
 
-	panic(fmt.Errorf("no matching piece found"))
-}
-
-func (game Game) GetImmediateMoves() []Move {
-	result := []Move{}
+	if !isSquare(str[:2]) || !isSquare(str[2:4]) {
+		return Move{}, fmt.Errorf("invalid move %s: malformed square", str)
+	}
 
-	board := game.Board
-	for row := 0; row < len(board); row++ {
-		for col := 0; col < len(board[row]); col++ {
-			piece := board[row][col]
+	if len(str) == 5 {
+		if _, err := getPromotionPiece(str[4]); err != nil || str[4] < 'a' {
+			return Move{}, fmt.Errorf("invalid move %s: unknown promotion %c", str, str[4])
+		}
+	}
 
-			if piece == 0 || piece.GetColor() != game.Active {
-				continue
-			}
+	for _, move := range game.GetMoves() {
+		if move.IsCastle() && move.UCIChess960() == str {
+			return move, nil
+		}
 
-			result = append(result, game.getMovesFor(CreateCoordInt(row, col))...)
+		if move.UCI() == str && !(move.IsCastle() && game.Chess960) {
+			return move, nil
 		}
 	}
 
-	return result
+	return Move{}, fmt.Errorf("illegal move %s", str)
 }
 
-func (game Game) GetMoves() []Move {
-	result := []Move{}
+func getPromotionPiece(c byte) (Piece, error) {
+	piece, err := GetPiece(rune(c))
+	if err != nil {
+		return 0, err
+	}
 
-	board := game.Board
-	pieces := 0
-	startRow := 0
-	rowDir := 1
-	if game.Active == Black {
-		startRow = 7
-		rowDir = -1
+	switch piece.GetType() {
+	case Knight, Bishop, Rook, Queen:
+		return piece.GetType(), nil
 	}
 
-	for row := startRow; row < len(board) && row >= 0 && pieces < 16; row += rowDir {
-		for col := 0; col < len(board[row]) && pieces < 16; col++ {
-			piece := board[row][col]
+	return 0, fmt.Errorf("cannot promote to %c", c)
+}
+
+// Whether from is on the file and/or rank given by disambiguation
+func matchesDisambiguation(from Coordinate, disambiguation string) bool {
+	algebra := from.GetAlgebra()
 
-			if piece == 0 || piece.GetColor() != game.Active {
-				continue
+	for _, c := range disambiguation {
+		switch {
+		case c >= 'a' && c <= 'h':
+			if rune(algebra[0]) != c {
+				return false
 			}
-
-			pieces++
-
-			psuedo := game.getMovesFor(CreateCoordInt(row, col))
-			legalMoves := []Move{}
-
-			for _, psuedoMove := range psuedo {
-				if psuedoMove.Capture.GetType() == King {
-					continue
-				}
-
-				game.MakeMove(psuedoMove)
-				enemyMoves := game.GetImmediateMoves()
-
-				legal := true
-				for _, enemyMove := range enemyMoves {
-					if enemyMove.Capture.GetType() == King {
-						legal = false
-						break
-					}
-					if psuedoMove.IsCastle() {
-						enemyRow, enemyCol := enemyMove.To.GetCoords()
-						targetRow, targetCol := psuedoMove.To.GetCoords()
-
-						if enemyRow == targetRow && enemyCol == 4 {
-							// Cannot castle out of check
-							legal = false
-							break
-						}
-
-						if enemyMove.Capture.GetType() == Rook {
-							if targetRow != enemyRow {
-								continue
-							}
-							if targetCol == 0 && enemyCol == 3 {
-								legal = false
-								break
-							}
-							if targetCol == 7 && enemyCol == 5 {
-								legal = false
-								break
-							}
-						}
-					}
-				}
-
-				if legal {
-					legalMoves = append(legalMoves, psuedoMove)
-				}
-
-				game.UndoMove()
+		case c >= '1' && c <= '8':
+			if rune(algebra[1]) != c {
+				return false
 			}
-
-			result = append(result, legalMoves...)
+		default:
+			return false
 		}
 	}
 
-	return result
+	return true
 }
 
-func (game Game) getMovesFor(coord Coordinate) []Move {
-	piece := game.Get(coord)
+func isSquare(str string) bool {
+	return len(str) == 2 && str[0] >= 'a' && str[0] <= 'h' && str[1] >= '1' && str[1] <= '8'
+}
 
-	switch piece.GetType() {
-	case Pawn:
-		return game.getPawnMoves(coord)
-	case Knight:
-		return game.getKnightMoves(coord)
-	case Bishop:
-		return game.getBishopMoves(coord)
-	case Rook:
-		return game.getRookMoves(coord)
-	case Queen:
-		return game.getQueenMoves(coord)
-	case King:
-		return game.getKingMoves(coord)
-	default:
-		panic(fmt.Errorf("unknown piece type: %v", piece))
+// The game's bitboards, built from the board
+// if the game was not created through FromFEN.
+func (game Game) bitboards() *bitboards {
+	if game.bits != nil {
+		return game.bits
 	}
+
+	return newBitboards(game.Board)
 }
 
-func (game Game) getPawnMoves(coord Coordinate) []Move {
-	piece := game.Get(coord)
-	moves := []Move{}
-	direction := 1
-	if piece.GetColor() == Black {
-		direction = -1
-	}
+// Returns all pseudo-legal moves for the active player,
+// i.e. moves that may leave the player's own king in check.
+func (game Game) GetImmediateMoves() []Move {
+	bits := game.bitboards()
+	result := []Move{}
 
-	// Basic pushing
-	row, col := coord.GetCoords()
-	if piece.GetColor() == White {
-		if row == 1 && game.Board[row+1][col] == 0 {
-			moves = append(moves, game.CreateMove(coord, CreateCoordByte(row+2, col)))
-		}
+	bits.colors[colorIndex(game.Active)].forEach(func(square int) {
+		result = game.appendMovesFor(result, bits, coordOf(square))
+	})
+
+	return result
+}
+
+func (game Game) GetMoves() []Move {
+	bits := game.bitboards()
+	us := colorIndex(game.Active)
+	legal := newLegality(bits, us)
+	pseudo := make([]Move, 0, 48)
+
+	if bitsSet(legal.checkers) > 1 {
+		// Only the king can get out of a double check
+		pseudo = game.appendMovesFor(pseudo, bits, coordOf(legal.king))
 	} else {
-		if row == 6 && game.Board[row-1][col] == 0 {
-			moves = append(moves, game.CreateMove(coord, CreateCoordByte(row-2, col)))
-		}
+		bits.colors[us].forEach(func(square int) {
+			pseudo = game.appendMovesFor(pseudo, bits, coordOf(square))
+		})
 	}
-	moves = append(moves, game.CreateMove(coord, CreateCoordInt(int(row)+direction, int(col))))
-	moves = filter(moves, func(m Move) bool {
-		return m.Capture == 0
-	})
 
-	// Capturing
-	for _, dx := range []int{-1, 1} {
-		if col+byte(dx) > 7 {
+	// Filter in place, legal moves never outnumber pseudo-legal ones
+	result := pseudo[:0]
+	for _, move := range pseudo {
+		if move.Capture.GetType() == King {
 			continue
 		}
-		capture := game.CreateMove(coord, CreateCoordInt(int(row)+direction, int(col)+dx))
-		if capture.Capture != 0 && capture.Piece.GetColor() != capture.Capture.GetColor() {
-			moves = append(moves, capture)
-		}
-	}
 
-	// Promoting
-	for _, move := range moves {
-		row, _ := move.To.GetCoords()
-
-		if row == 0 || row == 7 {
-			// A 0 promotionTo defaults to Queen for simplicity
-			for _, piece := range []Piece{Knight, Bishop, Rook} {
-				move.promotionTo = piece | move.Piece.GetColor()
-				moves = append(moves, move)
-			}
+		if legal.allows(move) {
+			result = append(result, move)
 		}
 	}
 
-	// En Passant
+	return result
+}
 
-	if game.EnPassant != nil {
-		enRow, enCol := (*game.EnPassant).GetCoords()
+// What is needed to tell whether a move leaves the mover's king safe,
+// worked out once per position rather than by making each move
+type legality struct {
+	bits     *bitboards
+	us, them int
+	// 64 if there is no king
+	king     int
+	checkers bitboard
+	pinned   bitboard
+	// Squares that capture or block a single checker, or every square when not in check
+	evasions bitboard
+}
 
-		if int(enRow) == int(row)+direction {
-			diff := int(enCol) - int(col)
-			if diff == -1 || diff == 1 {
-				move := game.CreateMove(coord, *game.EnPassant)
-				move.isEnPassant = true
-				moves = append(moves, move)
-			}
-		}
+func newLegality(bits *bitboards, us int) legality {
+	legal := legality{
+		bits:     bits,
+		us:       us,
+		them:     1 - us,
+		king:     bits.king(us),
+		checkers: bits.checkers(us),
+		pinned:   bits.pinned(us),
+		evasions: ^bitboard(0),
 	}
 
-	return moves
+	if legal.checkers != 0 {
+		checker := legal.checkers.first()
+		legal.evasions = legal.checkers | between[legal.king][checker]
+	}
+
+	return legal
 }
 
-func (game Game) getKnightMoves(coord Coordinate) []Move {
-	moves := []Move{}
+func (legal legality) allows(move Move) bool {
+	if legal.king == 64 {
+		// No king to leave in check
+		return true
+	}
 
-	offsets := [][]int{{-2, 1}, {-1, 2}, {1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}}
-	sx, sy := coord.GetCoords()
+	from, to := squareOf(move.From), squareOf(move.To)
+	switch {
+	case move.IsCastle():
+		return legal.allowsCastle(move)
+	case from == legal.king:
+		// Without the king, so it can't hide behind itself from a slider
+		occ := legal.bits.occupied &^ (1 << from)
+		return legal.bits.attackers(to, occ)&legal.bits.colors[legal.them] == 0
+	case move.isEnPassant:
+		// Removes two pieces from the rank, so is simplest to play out
+		after := *legal.bits
+		after.apply(move)
+		return !after.attacked(legal.king, legal.them)
+	}
 
-	for _, offset := range offsets {
-		tx := sx + byte(offset[0])
-		ty := sy + byte(offset[1])
+	if !legal.evasions.has(to) {
+		return false
+	}
 
-		if tx > 7 || ty > 7 {
-			continue
-		}
+	// A pinned piece may only move along the pin
+	return !legal.pinned.has(from) || lines[legal.king][from].has(to)
+}
 
-		moves = append(moves, game.CreateMove(coord, coord.Add(offset[0], offset[1])))
+// Cannot castle out of, or through, check
+func (legal legality) allowsCastle(move Move) bool {
+	if legal.checkers != 0 {
+		return false
 	}
 
-	moves = game.filterAllies(moves)
-	return moves
-}
+	row, from := move.From.GetCoords()
+	king, rook := move.castleSquares()
+	_, to := king.GetCoords()
 
-func (game Game) getBishopMoves(coord Coordinate) []Move {
-	return game.getSlidingMovesOf(coord, [][]int{{-1, 1}, {1, 1}, {1, -1}, {-1, -1}})
-}
+	for col := min(from, to); col <= max(from, to); col++ {
+		if legal.bits.attacked(int(row)*8+int(col), legal.them) {
+			return false
+		}
+	}
 
-func (game Game) getRookMoves(coord Coordinate) []Move {
-	return game.getSlidingMovesOf(coord, [][]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}})
+	// The rook may have been shielding the king's destination
+	occ := legal.bits.occupied &^ (1<<squareOf(move.From) | 1<<squareOf(move.To))
+	occ |= 1<<squareOf(king) | 1<<squareOf(rook)
+	return legal.bits.attackers(squareOf(king), occ)&legal.bits.colors[legal.them] == 0
 }
 
-func (game Game) getQueenMoves(coord Coordinate) []Move {
-	return append(game.getBishopMoves(coord), game.getRookMoves(coord)...)
+func (game Game) getMovesFor(coord Coordinate) []Move {
+	return game.appendMovesFor([]Move{}, game.bitboards(), coord)
 }
 
-func (game Game) getKingMoves(coord Coordinate) []Move {
-	moves := []Move{}
-
-	offsets := [][]int{{-1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}}
-	sx, sy := coord.GetCoords()
+func (game Game) appendMovesFor(moves []Move, bits *bitboards, coord Coordinate) []Move {
+	piece := game.Get(coord)
+	square := squareOf(coord)
+	own := bits.colors[colorIndex(piece)]
 
-	for _, offset := range offsets {
-		tx := sx + byte(offset[0])
-		ty := sy + byte(offset[1])
+	var targets bitboard
+	switch piece.GetType() {
+	case Pawn:
+		return game.appendPawnMoves(moves, bits, coord)
+	case Knight:
+		targets = knightAttacks[square]
+	case Bishop:
+		targets = bishopAttacks(square, bits.occupied)
+	case Rook:
+		targets = rookAttacks(square, bits.occupied)
+	case Queen:
+		targets = bishopAttacks(square, bits.occupied) | rookAttacks(square, bits.occupied)
+	case King:
+		targets = kingAttacks[square]
+	default:
+		panic(fmt.Errorf("unknown piece type: %v", piece))
+	}
 
-		if tx > 7 || ty > 7 {
-			continue
-		}
+	(targets &^ own).forEach(func(target int) {
+		moves = append(moves, game.CreateMove(coord, coordOf(target)))
+	})
 
-		toCoord := CreateCoordByte(tx, ty)
-		moves = append(moves, game.CreateMove(coord, toCoord))
+	if piece.GetType() == King {
+		moves = game.appendCastleMoves(moves, bits, coord)
 	}
 
-	moves = game.filterAllies(moves)
-	return append(moves, game.getCastleMoves(coord)...)
+	return moves
 }
 
-func (game Game) getCastleMoves(coord Coordinate) []Move {
-	moves := []Move{}
-	castling := game.WhiteCastling
-	castleRow := 0
-
-	pawnCheckRow := 1
+func (game Game) appendPawnMoves(moves []Move, bits *bitboards, coord Coordinate) []Move {
+	piece := game.Get(coord)
+	square := squareOf(coord)
+	us := colorIndex(piece)
+	row, _ := coord.GetCoords()
 
-	if game.Active == Black {
-		castling = game.BlackCastling
-		castleRow = 7
-		pawnCheckRow = 6
+	forward, startRow, lastRow := 8, 1, 7
+	if us == 1 {
+		forward, startRow, lastRow = -8, 6, 0
 	}
 
-	// Edge case where once the king has moved, the pawn would
-	// no longer be able to capture.
-	// But the pawn being there in the first place already marks
-	// the king in check, regardless of the pawn's ability to capture.
-	enemyPawn := (^game.Active).GetColor() | Pawn
-	for _, col := range []int{3, 5} {
-		piece := game.Board[pawnCheckRow][col]
-		if piece != enemyPawn {
-			continue
-		}
-		return moves
-	}
+	// Basic pushing
+	var targets bitboard
+	push := square + forward
+	if push >= 0 && push < 64 && !bits.occupied.has(push) {
+		targets |= 1 << push
 
-	if castling.KingSide {
-		if game.Board[castleRow][5] == 0 && game.Board[castleRow][6] == 0 {
-			moves = append(moves, game.CreateMove(coord, CreateCoordInt(castleRow, 7)))
+		if int(row) == startRow && !bits.occupied.has(push+forward) {
+			targets |= 1 << (push + forward)
 		}
 	}
 
-	if castling.QueenSide {
-		for col := 1; col <= 3; col++ {
-			if game.Board[castleRow][col] != 0 {
-				return moves
-			}
-		}
+	// Capturing
+	targets |= pawnAttacks[us][square] & bits.colors[1-us]
 
-		moves = append(moves, game.CreateMove(coord, CreateCoordInt(castleRow, 0)))
-	}
-	return moves
-}
+	targets.forEach(func(target int) {
+		move := game.CreateMove(coord, coordOf(target))
+		moves = append(moves, move)
 
-func (game Game) getSlidingMovesOf(coord Coordinate, offsets [][]int) []Move {
-	moves := []Move{}
+		// Promoting
+		if target/8 == lastRow {
+			// A 0 promotionTo defaults to Queen for simplicity
+			for _, promotion := range []Piece{Knight, Bishop, Rook} {
+				move.promotionTo = promotion | piece.GetColor()
+				moves = append(moves, move)
+			}
+		}
+	})
 
-	for _, offset := range offsets {
-		moves = append(moves, game.getSlidingMoves(coord, offset[0], offset[1])...)
+	// En Passant, only if the pawn that skipped the square is there to be taken
+	// (FromFEN does not check, see ValidateFEN)
+	if game.EnPassant != nil && pawnAttacks[us][square].has(squareOf(*game.EnPassant)) &&
+		bits.pieces[1-us][pawnIndex].has(squareOf(*game.EnPassant)-8+16*us) {
+		move := game.CreateMove(coord, *game.EnPassant)
+		move.isEnPassant = true
+		moves = append(moves, move)
 	}
 
 	return moves
 }
 
-func (game Game) getSlidingMoves(coord Coordinate, offsetX int, offsetY int) []Move {
-	moves := []Move{}
-
-	current := coord
+// Castling is represented as the king capturing its own rook,
+// whether the king passes through check is left to the legality filter.
+// Outside of Chess960 the king must be on the e-file and the rooks in the corners.
+func (game Game) appendCastleMoves(moves []Move, bits *bitboards, coord Coordinate) []Move {
+	king := game.Get(coord)
+	castling := game.WhiteCastling
+	castleRow := 0
 
-	for {
-		cRow, cCol := current.GetCoords()
+	if king.GetColor() == Black {
+		castling = game.BlackCastling
+		castleRow = 7
+	}
 
-		cRow += byte(offsetY)
-		cCol += byte(offsetX)
+	row, kingCol := coord.GetCoords()
+	if int(row) != castleRow || (!game.Chess960 && kingCol != 4) {
+		return moves
+	}
 
-		if cRow > 7 || cCol > 7 {
-			break
+	rook := Rook | king.GetColor()
+	for _, kingSide := range []bool{true, false} {
+		if (kingSide && !castling.KingSide) || (!kingSide && !castling.QueenSide) {
+			continue
 		}
 
-		current = CreateCoordByte(cRow, cCol)
-
-		move := game.CreateMove(coord, current)
-
-		if move.Capture == 0 {
-			moves = append(moves, move)
+		rookCol := game.rookFile(king, kingSide)
+		if game.Board[castleRow][rookCol] != rook || (rookCol > int(kingCol)) != kingSide {
 			continue
 		}
 
-		if move.Piece.GetColor() != move.Capture.GetColor() {
-			moves = append(moves, move)
+		move := game.CreateMove(coord, CreateCoordInt(castleRow, rookCol))
+		kingTo, rookTo := move.castleSquares()
+		_, kingToCol := kingTo.GetCoords()
+		_, rookToCol := rookTo.GetCoords()
+
+		// Besides the king and rook themselves, every square
+		// either crosses or lands on must be empty
+		low := min(int(kingCol), rookCol, int(kingToCol), int(rookToCol))
+		high := max(int(kingCol), rookCol, int(kingToCol), int(rookToCol))
+		empty := true
+		for col := low; col <= high; col++ {
+			if col != int(kingCol) && col != rookCol && bits.occupied.has(castleRow*8+col) {
+				empty = false
+			}
 		}
-		break
-	}
-
-	return moves
-}
 
-func filter[T any](arr []T, predicate func(T) bool) []T {
-	ret := []T{}
-	for _, t := range arr {
-		if !predicate(t) {
-			continue
+		if empty {
+			moves = append(moves, move)
 		}
-
-		ret = append(ret, t)
 	}
 
-	return ret
-}
-
-func (board Game) filterAllies(moves []Move) []Move {
-	return filter(moves, func(m Move) bool {
-		return m.Capture == 0 || m.Piece.GetColor() != m.Capture.GetColor()
-	})
+	return moves
 }
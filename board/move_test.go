@@ -3,6 +3,8 @@ package board
 import (
 	"strconv"
 	"testing"
+
+	"github.com/msws/chess/tt"
 )
 
 func getStartMovesForType(piece Piece) []Move {
@@ -128,6 +130,211 @@ func TestIsCastle(t *testing.T) {
 	})
 }
 
+func TestPromotion(t *testing.T) {
+	board, err := FromFEN("8/3P4/8/8/8/8/4p3/8 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		move := board.CreateMoveStr("d7", "d8")
+		if move.Promotion() != White|Queen {
+			t.Errorf("expected promotion to be %v, got %v", White|Queen, move.Promotion())
+		}
+	})
+
+	t.Run("Under-Promotion", func(t *testing.T) {
+		move := NewPromotion(CreateCoordAlgebra("e2"), CreateCoordAlgebra("e1"), Black|Knight)
+		if move.Promotion() != Black|Knight {
+			t.Errorf("expected promotion to be %v, got %v", Black|Knight, move.Promotion())
+		}
+
+		if !move.IsPromotion() {
+			t.Error("expected move to be a promotion")
+		}
+
+		board.MakeMove(move)
+		defer board.UndoMove()
+
+		if piece := board.Get(CreateCoordAlgebra("e1")); piece != Black|Knight {
+			t.Errorf("expected %v on e1, got %v", Black|Knight, piece)
+		}
+	})
+
+	t.Run("Not Promoting", func(t *testing.T) {
+		move := board.CreateMoveStr("d7", "d6")
+		if move.Promotion() != 0 {
+			t.Errorf("expected no promotion, got %v", move.Promotion())
+		}
+	})
+}
+
+func TestIsCapture(t *testing.T) {
+	tests := map[string]struct {
+		fen       string
+		from, to  string
+		capture   bool
+		enPassant bool
+	}{
+		"Quiet":      {START_POSITION, "e2", "e4", false, false},
+		"Capture":    {"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4", "d5", true, false},
+		"En Passant": {"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5", "d6", true, true},
+		"Castle":     {"4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1", "h1", false, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var move Move
+			for _, legal := range game.GetMoves() {
+				if legal.From.GetAlgebra() == test.from && legal.To.GetAlgebra() == test.to {
+					move = legal
+				}
+			}
+
+			if move.IsCapture() != test.capture {
+				t.Errorf("expected IsCapture to be %v", test.capture)
+			}
+
+			if move.IsEnPassant() != test.enPassant {
+				t.Errorf("expected IsEnPassant to be %v", test.enPassant)
+			}
+		})
+	}
+}
+
+func TestMoveGetAlgebra(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		from, to string
+		expected string
+	}{
+		"Pawn Push": {
+			fen:      START_POSITION,
+			from:     "e2",
+			to:       "e4",
+			expected: "e4",
+		},
+		"Pawn Capture": {
+			fen:      "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2",
+			from:     "e4",
+			to:       "d5",
+			expected: "exd5",
+		},
+		"En Passant": {
+			fen:      "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			from:     "e5",
+			to:       "f6",
+			expected: "exf6",
+		},
+		"File Disambiguation": {
+			fen:      "k7/8/8/8/8/8/8/KN3N2 w - - 0 1",
+			from:     "b1",
+			to:       "d2",
+			expected: "Nbd2",
+		},
+		"Rank Disambiguation": {
+			fen:      "k7/8/8/8/8/5N2/8/K4N2 w - - 0 1",
+			from:     "f3",
+			to:       "h2",
+			expected: "N3h2",
+		},
+		"Square Disambiguation": {
+			fen:      "8/8/1k6/8/4Q2Q/8/8/K6Q w - - 0 1",
+			from:     "h4",
+			to:       "e1",
+			expected: "Qh4e1",
+		},
+		"Pinned Piece Not Ambiguous": {
+			fen:      "4r2k/8/8/1N6/8/8/4N3/4K3 w - - 0 1",
+			from:     "b5",
+			to:       "d4",
+			expected: "Nd4",
+		},
+		"Capture": {
+			fen:      "k7/8/8/3q4/8/8/8/K2R4 w - - 0 1",
+			from:     "d1",
+			to:       "d5",
+			expected: "Rxd5",
+		},
+		"Promotion": {
+			fen:      "8/3P4/8/8/8/8/8/k1K5 w - - 0 1",
+			from:     "d7",
+			to:       "d8",
+			expected: "d8=Q",
+		},
+		"Promotion Capture": {
+			fen:      "8/8/8/8/8/2K5/6p1/k6R b - - 0 1",
+			from:     "g2",
+			to:       "h1",
+			expected: "gxh1=Q",
+		},
+		"Check": {
+			fen:      "k7/8/8/8/8/8/8/K2R4 w - - 0 1",
+			from:     "d1",
+			to:       "d8",
+			expected: "Rd8+",
+		},
+		"Checkmate": {
+			fen:      "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			from:     "a1",
+			to:       "a8",
+			expected: "Ra8#",
+		},
+		"King-Side Castle": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			from:     "e1",
+			to:       "h1",
+			expected: "O-O",
+		},
+		"Queen-Side Castle With Check": {
+			fen:      "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1",
+			from:     "e1",
+			to:       "a1",
+			expected: "O-O-O+",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var move Move
+			for _, legal := range game.GetMoves() {
+				if legal.From.GetAlgebra() == test.from && legal.To.GetAlgebra() == test.to {
+					move = legal
+					break
+				}
+			}
+
+			result := move.GetAlgebra(game)
+			if result != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, result)
+			}
+
+			if game.ToFEN() != test.fen {
+				t.Errorf("formatting should not change the game, got %v", game.ToFEN())
+			}
+
+			parsed, err := game.ParseSAN(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if parsed != move {
+				t.Errorf("expected %v to parse back to %v, got %v", result, move, parsed)
+			}
+		})
+	}
+}
+
 func TestCreateMove(t *testing.T) {
 	t.Run("Basic Pawn Push", func(t *testing.T) {
 		start := getStartGame()
@@ -185,6 +392,69 @@ func testForBothColors(t *testing.T, test func(color Piece)) {
 	})
 }
 
+func TestPerftParallel(t *testing.T) {
+	for name, test := range getPerfData() {
+		t.Run(name, func(t *testing.T) {
+			start, err := FromFEN(test.FEN)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			depth := min(len(test.knownPerfs), 3)
+			for _, workers := range []int{0, 1, 4} {
+				// A fresh table each time, so no count is answered by an earlier run
+				for _, table := range []*tt.Table[Move]{nil, tt.New[Move](1)} {
+					calculated := start.PerftParallel(depth, workers, table)
+					if calculated != test.knownPerfs[depth-1] {
+						t.Errorf("expected %v nodes with %d workers (cached: %v), got %v",
+							test.knownPerfs[depth-1], workers, table != nil, calculated)
+					}
+				}
+			}
+
+			if start.ToFEN() != test.FEN {
+				t.Errorf("perft should not change the game, got %v", start.ToFEN())
+			}
+		})
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	benchmarkPerft(b, func(game *Game, depth int) int {
+		return game.Perft(depth)
+	})
+}
+
+func BenchmarkPerftParallel(b *testing.B) {
+	benchmarkPerft(b, func(game *Game, depth int) int {
+		return game.PerftParallel(depth, 0, perftTable())
+	})
+}
+
+// Runs perft on each of getPerfData's positions at their deepest
+// known depth, clearing the shared table so each iteration does the full work
+func benchmarkPerft(b *testing.B, perft func(game *Game, depth int) int) {
+	for name, test := range getPerfData() {
+		b.Run(name, func(b *testing.B) {
+			game, err := FromFEN(test.FEN)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			depth := len(test.knownPerfs)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				perftTable().Clear()
+				b.StartTimer()
+
+				if nodes := perft(game, depth); nodes != test.knownPerfs[depth-1] {
+					b.Fatalf("expected %v nodes, got %v", test.knownPerfs[depth-1], nodes)
+				}
+			}
+		})
+	}
+}
+
 func getPerfData() map[string]struct {
 	FEN        string
 	knownPerfs []int
@@ -194,7 +464,7 @@ func getPerfData() map[string]struct {
 		knownPerfs []int
 	}{
 		"Starting Position": {
-			knownPerfs: []int{20, 400, 8902, 197281, 4865609 /* 119060324, 3195901860*/},
+			knownPerfs: []int{20, 400, 8902, 197281, 4865609},
 			FEN:        START_POSITION,
 		},
 		"Nf3 g5": {
@@ -206,7 +476,7 @@ func getPerfData() map[string]struct {
 			knownPerfs: []int{20, 401, 9062, 204508},
 		},
 		"Kiwipete": {
-			knownPerfs: []int{48, 2039, 97862, 4085603 /*, 193690690 */},
+			knownPerfs: []int{48, 2039, 97862, 4085603},
 			FEN:        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 0",
 		},
 		"Kiwipete - Passant": {
@@ -247,3 +517,246 @@ func getPerfData() map[string]struct {
 		},
 	}
 }
+
+func TestMoveUCI(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		from, to string
+		expected string
+	}{
+		"Pawn Push": {
+			fen:      START_POSITION,
+			from:     "e2",
+			to:       "e4",
+			expected: "e2e4",
+		},
+		"King-Side Castle": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			from:     "e1",
+			to:       "h1",
+			expected: "e1g1",
+		},
+		"Queen-Side Castle": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			from:     "e8",
+			to:       "a8",
+			expected: "e8c8",
+		},
+		"Promotion": {
+			fen:      "8/3P4/8/8/8/8/8/k1K5 w - - 0 1",
+			from:     "d7",
+			to:       "d8",
+			expected: "d7d8q",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := game.CreateMoveStr(test.from, test.to).UCI()
+			if result != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseUCIMove(t *testing.T) {
+	game, err := FromFEN("8/3P4/8/8/8/8/8/k1K5 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, str := range []string{"d7d8q", "d7d8r", "d7d8b", "d7d8n"} {
+		t.Run(str, func(t *testing.T) {
+			move, err := game.ParseUCIMove(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if move.UCI() != str {
+				t.Errorf("expected %v, got %v", str, move.UCI())
+			}
+		})
+	}
+
+	t.Run("Missing Promotion", func(t *testing.T) {
+		if _, err := game.ParseUCIMove("d7d8"); err == nil {
+			t.Error("expected error for promotion without a piece")
+		}
+	})
+
+	t.Run("Castling", func(t *testing.T) {
+		castling, err := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, str := range []string{"e1g1", "e1h1"} {
+			move, err := castling.ParseUCIMove(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !move.IsCastle() || move.UCI() != "e1g1" || move.UCIChess960() != "e1h1" {
+				t.Errorf("expected %v to castle king-side, got %v", str, move)
+			}
+		}
+	})
+
+	t.Run("Chess960 Castling", func(t *testing.T) {
+		castling, err := FromFENLenient("rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// b1c1 is also where the king lands castling queen-side,
+		// which in Chess960 must be written as b1a1
+		move, err := castling.ParseUCIMove("b1c1")
+		if err != nil || move.IsCastle() {
+			t.Errorf("expected b1c1 to be a king move, got %v (%v)", move, err)
+		}
+
+		move, err = castling.ParseUCIMove("b1a1")
+		if err != nil || !move.IsCastle() {
+			t.Errorf("expected b1a1 to castle, got %v (%v)", move, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, str := range []string{"", "e7", "d7d9", "i7d8", "d7d8k", "d7d8Q", "d7d8qq", "a1a2"} {
+			if move, err := game.ParseUCIMove(str); err == nil {
+				t.Errorf("expected error for %q, got %v", str, move)
+			}
+		}
+	})
+}
+
+func TestChess960Castling(t *testing.T) {
+	tests := map[string]struct {
+		fen string
+		// Castles available, king takes rook, along with the position after each
+		castles map[string]string
+	}{
+		"King On B-File": {
+			fen: "rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1",
+			castles: map[string]string{
+				"b1h1": "rk5r/pppppppp/8/8/8/8/PPPPPPPP/R4RK1 b ha - 1 1",
+				"b1a1": "rk5r/pppppppp/8/8/8/8/PPPPPPPP/2KR3R b ha - 1 1",
+			},
+		},
+		"King Stays": {
+			fen: "1r4kr/8/8/8/8/8/8/1R4KR w HBhb - 0 1",
+			castles: map[string]string{
+				"g1h1": "1r4kr/8/8/8/8/8/8/1R3RK1 b hb - 1 1",
+				"g1b1": "1r4kr/8/8/8/8/8/8/2KR3R b hb - 1 1",
+			},
+		},
+		"King And Rook Swap": {
+			fen: "k7/8/8/8/8/8/8/2RK4 w C - 0 1",
+			castles: map[string]string{
+				"d1c1": "k7/8/8/8/8/8/8/2KR4 b - - 1 1",
+			},
+		},
+		"Rook Lands Beside King": {
+			fen: "6k1/8/8/8/8/8/8/5KR1 w G - 0 1",
+			castles: map[string]string{
+				"f1g1": "6k1/8/8/8/8/8/8/5RK1 b - - 1 1",
+			},
+		},
+		"Through Check": {
+			fen:     "4r2k/8/8/8/8/8/8/1K5R w H - 0 1",
+			castles: map[string]string{},
+		},
+		"Blocked Destination": {
+			fen:     "k7/8/8/8/8/8/8/1K3BNR w H - 0 1",
+			castles: map[string]string{},
+		},
+		"Rook Shields Destination": {
+			fen:     "4k3/8/8/8/8/8/8/rR2K3 w B - 0 1",
+			castles: map[string]string{},
+		},
+		"Black": {
+			fen: "rk5r/8/8/8/8/8/8/RK5R b HAha - 0 1",
+			castles: map[string]string{
+				"b8h8": "r4rk1/8/8/8/8/8/8/RK5R w HA - 1 2",
+				"b8a8": "2kr3r/8/8/8/8/8/8/RK5R w HA - 1 2",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFENLenient(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !game.Chess960 {
+				t.Fatal("expected a Chess960 game")
+			}
+
+			if game.ToFEN() != test.fen {
+				t.Errorf("expected FEN to round trip, got %v", game.ToFEN())
+			}
+
+			if strict, err := FromFEN(test.fen); err != nil || !strict.Chess960 || strict.Hash() != game.Hash() {
+				t.Errorf("expected strict FromFEN to read the Shredder-FEN the same, got %v", err)
+			}
+
+			hash := game.Hash()
+			castles := 0
+			for _, move := range game.GetMoves() {
+				if !move.IsCastle() {
+					continue
+				}
+				castles++
+
+				expected, ok := test.castles[move.UCIChess960()]
+				if !ok {
+					t.Errorf("unexpected castle %v", move.UCIChess960())
+					continue
+				}
+
+				san := move.GetAlgebra(game)
+				if parsed, err := game.ParseSAN(san); err != nil || parsed != move {
+					t.Errorf("expected %v to parse back to %v, got %v (%v)", san, move.UCIChess960(), parsed, err)
+				}
+
+				game.MakeMove(move)
+				if game.ToFEN() != expected {
+					t.Errorf("after %v expected %v, got %v", san, expected, game.ToFEN())
+				}
+
+				if game.Hash() != game.computeHash() {
+					t.Errorf("hash out of sync after %v", san)
+				}
+
+				game.UndoMove()
+				if game.ToFEN() != test.fen || game.Hash() != hash {
+					t.Errorf("undoing %v expected %v, got %v", san, test.fen, game.ToFEN())
+				}
+			}
+
+			if castles != len(test.castles) {
+				t.Errorf("expected %d castles, got %d", len(test.castles), castles)
+			}
+		})
+	}
+
+	t.Run("Rook Move Revokes Right", func(t *testing.T) {
+		game, err := FromFENLenient("1r4kr/8/8/8/8/8/8/1R4KR w HBhb - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		game.MakeMove(game.CreateMoveStr("b1", "b2"))
+		if fen := game.ToFEN(); fen != "1r4kr/8/8/8/8/8/1R6/6KR b Hhb - 1 1" {
+			t.Errorf("expected queen-side right to be revoked, got %v", fen)
+		}
+	})
+}
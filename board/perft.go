@@ -0,0 +1,117 @@
+package board
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// A breakdown of the leaf nodes counted by perft, matching the columns
+// of the perft results tables on the Chess Programming Wiki.
+type PerftCounts struct {
+	Nodes           int
+	Captures        int
+	EnPassant       int
+	Castles         int
+	Promotions      int
+	Checks          int
+	DiscoveryChecks int
+	DoubleChecks    int
+	Checkmates      int
+}
+
+func (counts *PerftCounts) add(other PerftCounts) {
+	counts.Nodes += other.Nodes
+	counts.Captures += other.Captures
+	counts.EnPassant += other.EnPassant
+	counts.Castles += other.Castles
+	counts.Promotions += other.Promotions
+	counts.Checks += other.Checks
+	counts.DiscoveryChecks += other.DiscoveryChecks
+	counts.DoubleChecks += other.DoubleChecks
+	counts.Checkmates += other.Checkmates
+}
+
+func (counts PerftCounts) String() string {
+	return fmt.Sprintf("nodes %d captures %d e.p. %d castles %d promotions %d checks %d discovery checks %d double checks %d checkmates %d",
+		counts.Nodes, counts.Captures, counts.EnPassant, counts.Castles, counts.Promotions,
+		counts.Checks, counts.DiscoveryChecks, counts.DoubleChecks, counts.Checkmates)
+}
+
+// Like Perft, but also classifies the moves leading to each leaf node.
+// Results are not cached, so this is considerably slower than Perft.
+func (game Game) PerftDetailed(depth int) PerftCounts {
+	if depth <= 0 {
+		return PerftCounts{Nodes: 1}
+	}
+
+	counts := PerftCounts{}
+
+	for _, move := range game.GetMoves() {
+		game.MakeMove(move)
+
+		if depth == 1 {
+			counts.add(game.classify(move))
+		} else {
+			counts.add(game.PerftDetailed(depth - 1))
+		}
+
+		game.UndoMove()
+	}
+
+	return counts
+}
+
+// Classifies move, which must be the last move made
+func (game Game) classify(move Move) PerftCounts {
+	counts := PerftCounts{Nodes: 1}
+
+	if move.IsCastle() {
+		counts.Castles++
+	}
+
+	if move.IsCapture() {
+		counts.Captures++
+	}
+
+	if move.IsEnPassant() {
+		counts.EnPassant++
+	}
+
+	if move.IsPromotion() {
+		counts.Promotions++
+	}
+
+	checkers := game.bitboards().checkers(colorIndex(game.Active))
+	if checkers == 0 {
+		return counts
+	}
+
+	counts.Checks++
+
+	// As on the wiki, double checks are not also counted as discovered
+	if bitsSet(checkers) > 1 {
+		counts.DoubleChecks++
+	} else if checkers&^movedSquares(move) != 0 {
+		counts.DiscoveryChecks++
+	}
+
+	if len(game.GetMoves()) == 0 {
+		counts.Checkmates++
+	}
+
+	return counts
+}
+
+// Squares the pieces moved by move end up on
+func movedSquares(move Move) bitboard {
+	if !move.IsCastle() {
+		return 1 << squareOf(move.To)
+	}
+
+	king, rook := move.castleSquares()
+	return 1<<squareOf(king) | 1<<squareOf(rook)
+}
+
+func bitsSet(bb bitboard) int {
+	return bits.OnesCount64(uint64(bb))
+}
@@ -0,0 +1,102 @@
+package board
+
+import "testing"
+
+func TestPerftDetailed(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected []PerftCounts
+	}{
+		"Starting Position": {
+			fen: START_POSITION,
+			expected: []PerftCounts{
+				{Nodes: 20},
+				{Nodes: 400},
+				{Nodes: 8902, Captures: 34, Checks: 12},
+				{Nodes: 197281, Captures: 1576, Checks: 469, Checkmates: 8},
+			},
+		},
+		"Kiwipete": {
+			fen: "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			expected: []PerftCounts{
+				{Nodes: 48, Captures: 8, Castles: 2},
+				{Nodes: 2039, Captures: 351, EnPassant: 1, Castles: 91, Checks: 3},
+				{Nodes: 97862, Captures: 17102, EnPassant: 45, Castles: 3162, Checks: 993, Checkmates: 1},
+			},
+		},
+		"3": {
+			fen: "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			expected: []PerftCounts{
+				{Nodes: 14, Captures: 1, Checks: 2},
+				{Nodes: 191, Captures: 14, Checks: 10},
+				{Nodes: 2812, Captures: 209, EnPassant: 2, Checks: 267, DiscoveryChecks: 3},
+				{Nodes: 43238, Captures: 3348, EnPassant: 123, Checks: 1680, DiscoveryChecks: 106, Checkmates: 17},
+				{Nodes: 674624, Captures: 52051, EnPassant: 1165, Checks: 52950, DiscoveryChecks: 1292, DoubleChecks: 3},
+			},
+		},
+		"4": {
+			fen: "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			expected: []PerftCounts{
+				{Nodes: 6},
+				{Nodes: 264, Captures: 87, Castles: 6, Promotions: 48, Checks: 10},
+				// Both discovered checks are from cxb6 opening the bishop on b4
+				{Nodes: 9467, Captures: 1021, EnPassant: 4, Promotions: 120, Checks: 38, DiscoveryChecks: 2, Checkmates: 22},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for depth, expected := range test.expected {
+				counts := game.PerftDetailed(depth + 1)
+				if counts != expected {
+					t.Errorf("depth %d expected\n%v\ngot\n%v", depth+1, expected, counts)
+				}
+			}
+
+			if game.ToFEN() != test.fen {
+				t.Errorf("perft should not change the game, got %v", game.ToFEN())
+			}
+		})
+	}
+}
+
+func TestPerftNonPositiveDepth(t *testing.T) {
+	game, err := FromFEN(START_POSITION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, depth := range []int{0, -1} {
+		if nodes := game.Perft(depth); nodes != 1 {
+			t.Errorf("expected Perft(%d) to count 1 node, got %d", depth, nodes)
+		}
+
+		if counts := game.PerftDetailed(depth); counts != (PerftCounts{Nodes: 1}) {
+			t.Errorf("expected PerftDetailed(%d) to count 1 node, got %v", depth, counts)
+		}
+	}
+}
+
+// Perft caches by hash, so changing the board with Set must change the hash
+func TestPerftAfterSet(t *testing.T) {
+	game, err := FromFEN(START_POSITION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nodes := game.Perft(3); nodes != 8902 {
+		t.Fatalf("expected 8902 nodes, got %d", nodes)
+	}
+
+	game.Set(CreateCoordAlgebra("e2"), 0)
+	if nodes := game.Perft(3); nodes != 17529 {
+		t.Errorf("expected 17529 nodes without the e2 pawn, got %d", nodes)
+	}
+}
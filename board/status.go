@@ -0,0 +1,167 @@
+package board
+
+// The state of a game, see Game.Status
+type Status int
+
+const (
+	Ongoing Status = iota
+	Checkmate
+	Stalemate
+
+	// Claimable draws
+	FiftyMoveDraw
+	ThreefoldRepetition
+
+	// Automatic draws
+	FivefoldRepetition
+	SeventyFiveMoveDraw
+	InsufficientMaterial
+)
+
+func (status Status) String() string {
+	switch status {
+	case Ongoing:
+		return "ongoing"
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case FiftyMoveDraw:
+		return "fifty-move draw"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case SeventyFiveMoveDraw:
+		return "seventy-five-move draw"
+	case InsufficientMaterial:
+		return "insufficient material"
+	}
+
+	return "unknown"
+}
+
+// Whether the active player's king is attacked
+func (game Game) InCheck() bool {
+	return game.bitboards().checkers(colorIndex(game.Active)) != 0
+}
+
+// Whether any piece of color by attacks coord
+func (game Game) IsSquareAttacked(coord Coordinate, by Piece) bool {
+	return game.bitboards().attacked(squareOf(coord), colorIndex(by))
+}
+
+// Pieces of either color attacking coord
+func (game Game) AttackersOf(coord Coordinate) []Coordinate {
+	bits := game.bitboards()
+	return coordsOf(bits.attackers(squareOf(coord), bits.occupied))
+}
+
+// Pieces giving check to the active player's king
+func (game Game) Checkers() []Coordinate {
+	return coordsOf(game.bitboards().checkers(colorIndex(game.Active)))
+}
+
+// Pieces of color that are pinned to their king, and so may only move along the pin
+func (game Game) PinnedPieces(color Piece) []Coordinate {
+	return coordsOf(game.bitboards().pinned(colorIndex(color)))
+}
+
+// Returns the state of the game from the active player's perspective.
+// Checkmate and stalemate take precedence over the draw rules,
+// and automatic draws take precedence over claimable ones.
+func (game Game) Status() Status {
+	if len(game.GetMoves()) == 0 {
+		if game.InCheck() {
+			return Checkmate
+		}
+		return Stalemate
+	}
+
+	if game.IsInsufficientMaterial() {
+		return InsufficientMaterial
+	}
+
+	if game.HalfMoves >= 150 {
+		return SeventyFiveMoveDraw
+	}
+
+	repetitions := game.RepetitionCount()
+	if repetitions >= 5 {
+		return FivefoldRepetition
+	}
+
+	if game.HalfMoves >= 100 {
+		return FiftyMoveDraw
+	}
+
+	if repetitions >= 3 {
+		return ThreefoldRepetition
+	}
+
+	return Ongoing
+}
+
+// Returns the color that won the game, if the game was won
+func (game Game) Winner() (Piece, bool) {
+	if game.Status() != Checkmate {
+		return 0, false
+	}
+
+	return (^game.Active).GetColor(), true
+}
+
+// Whether neither player has enough material left to checkmate,
+// i.e. only kings remain alongside either a single knight
+// or any number of bishops that all stand on the same colored squares.
+func (game Game) IsInsufficientMaterial() bool {
+	knights := 0
+	bishops := [2]int{} // Indexed by square color
+
+	for row := range game.Board {
+		for col, piece := range game.Board[row] {
+			switch piece.GetType() {
+			case 0, King:
+			case Knight:
+				knights++
+			case Bishop:
+				bishops[(row+col)%2]++
+			default:
+				return false
+			}
+		}
+	}
+
+	if knights == 0 {
+		return bishops[0] == 0 || bishops[1] == 0
+	}
+
+	return knights == 1 && bishops[0]+bishops[1] == 0
+}
+
+// Returns how many times the current position has occurred, including now.
+// Positions are identified by their hash, so the side to move,
+// castling rights and en passant square must all match.
+func (game Game) RepetitionCount() int {
+	count := 1
+
+	// Positions before the last capture or pawn move can never repeat
+	limit := len(game.HashHistory) - game.HalfMoves
+	if limit < 0 {
+		limit = 0
+	}
+
+	for i := len(game.HashHistory) - 2; i >= limit; i -= 2 {
+		if game.HashHistory[i] == game.hash {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Whether the active player may claim a draw,
+// either by threefold repetition or the fifty-move rule.
+func (game Game) CanClaimDraw() bool {
+	return game.RepetitionCount() >= 3 || game.HalfMoves >= 100
+}
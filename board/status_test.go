@@ -0,0 +1,375 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	shuffle := [][2]string{{"g1", "f3"}, {"g8", "f6"}, {"f3", "g1"}, {"f6", "g8"}}
+
+	tests := map[string]struct {
+		fen      string
+		shuffles int
+		expected Status
+	}{
+		"Ongoing": {
+			fen:      START_POSITION,
+			expected: Ongoing,
+		},
+		"Checkmate": {
+			fen:      "1R3k2/2R5/8/8/8/1K6/8/8 b - - 0 1",
+			expected: Checkmate,
+		},
+		"Stalemate": {
+			fen:      "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			expected: Stalemate,
+		},
+		"Bare Kings": {
+			fen:      "8/8/3k4/8/8/3K4/8/8 w - - 0 1",
+			expected: InsufficientMaterial,
+		},
+		"Fifty Moves": {
+			fen:      "8/8/3k4/8/8/3K4/3R4/8 w - - 100 80",
+			expected: FiftyMoveDraw,
+		},
+		"Seventy-Five Moves": {
+			fen:      "8/8/3k4/8/8/3K4/3R4/8 w - - 150 100",
+			expected: SeventyFiveMoveDraw,
+		},
+		"Mate On The Seventy-Fifth Move": {
+			fen:      "1R3k2/2R5/8/8/8/1K6/8/8 b - - 150 100",
+			expected: Checkmate,
+		},
+		"Twofold Repetition": {
+			fen:      START_POSITION,
+			shuffles: 1,
+			expected: Ongoing,
+		},
+		"Threefold Repetition": {
+			fen:      START_POSITION,
+			shuffles: 2,
+			expected: ThreefoldRepetition,
+		},
+		"Fivefold Repetition": {
+			fen:      START_POSITION,
+			shuffles: 4,
+			expected: FivefoldRepetition,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < test.shuffles; i++ {
+				for _, move := range shuffle {
+					game.MakeMove(game.CreateMoveStr(move[0], move[1]))
+				}
+			}
+
+			before := game.ToFEN()
+			if status := game.Status(); status != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, status)
+			}
+
+			if game.ToFEN() != before || len(game.Moves) != 4*test.shuffles {
+				t.Errorf("status should not change the game, got %v", game.ToFEN())
+			}
+		})
+	}
+}
+
+func TestWinner(t *testing.T) {
+	tests := map[string]struct {
+		fen    string
+		winner Piece
+		ok     bool
+	}{
+		"White Wins": {
+			fen:    "1R3k2/2R5/8/8/8/1K6/8/8 b - - 0 1",
+			winner: White,
+			ok:     true,
+		},
+		"Black Wins": {
+			fen:    "8/8/1k6/8/8/8/2r5/1r3K2 w - - 0 1",
+			winner: Black,
+			ok:     true,
+		},
+		"Stalemate": {
+			fen: "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+		},
+		"Ongoing": {
+			fen: START_POSITION,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			winner, ok := game.Winner()
+			if winner != test.winner || ok != test.ok {
+				t.Errorf("expected %v (%v), got %v (%v)", test.winner, test.ok, winner, ok)
+			}
+		})
+	}
+}
+
+func TestInCheck(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"Start":        {fen: START_POSITION, expected: false},
+		"Rook Check":   {fen: "k7/8/8/8/8/8/8/K6r w - - 0 1", expected: true},
+		"Pawn Check":   {fen: "k7/8/8/8/8/8/1p6/K7 w - - 0 1", expected: true},
+		"Blocked":      {fen: "k7/8/8/8/8/8/8/KN5r w - - 0 1", expected: false},
+		"Knight Check": {fen: "k7/8/8/8/8/1n6/8/K7 w - - 0 1", expected: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if game.InCheck() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, game.InCheck())
+			}
+		})
+	}
+}
+
+func TestRepetitionCount(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		moves    [][2]string
+		expected int
+	}{
+		"No Moves": {
+			fen:      START_POSITION,
+			expected: 1,
+		},
+		"Knight Shuffle": {
+			fen:      START_POSITION,
+			moves:    [][2]string{{"g1", "f3"}, {"g8", "f6"}, {"f3", "g1"}, {"f6", "g8"}, {"g1", "f3"}, {"g8", "f6"}, {"f3", "g1"}, {"f6", "g8"}},
+			expected: 3,
+		},
+		"Different Side To Move": {
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 0 1",
+			moves:    [][2]string{{"a1", "a2"}, {"e8", "d8"}, {"a2", "a3"}, {"d8", "e8"}, {"a3", "a1"}},
+			expected: 1,
+		},
+		"Castling Rights Lost": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			moves:    [][2]string{{"a1", "b1"}, {"a8", "b8"}, {"b1", "a1"}, {"b8", "a8"}, {"a1", "b1"}, {"a8", "b8"}, {"b1", "a1"}, {"b8", "a8"}},
+			expected: 2,
+		},
+		"En Passant Lost": {
+			fen:      "4k3/8/8/8/4p3/8/3P4/4K3 w - - 0 1",
+			moves:    [][2]string{{"d2", "d4"}, {"e8", "d8"}, {"e1", "d1"}, {"d8", "e8"}, {"d1", "e1"}, {"e8", "d8"}, {"e1", "d1"}, {"d8", "e8"}, {"d1", "e1"}},
+			expected: 2,
+		},
+		"After Pawn Move": {
+			fen:      "4k3/8/8/8/8/8/3P4/4K3 w - - 0 1",
+			moves:    [][2]string{{"e1", "d1"}, {"e8", "d8"}, {"d1", "e1"}, {"d8", "e8"}, {"d2", "d3"}, {"e8", "d8"}, {"e1", "d1"}, {"d8", "e8"}, {"d1", "e1"}},
+			expected: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, move := range test.moves {
+				game.MakeMove(game.CreateMoveStr(move[0], move[1]))
+			}
+
+			if count := game.RepetitionCount(); count != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, count)
+			}
+
+			if game.CanClaimDraw() != (test.expected >= 3) {
+				t.Errorf("expected CanClaimDraw to be %v", test.expected >= 3)
+			}
+
+			for range test.moves {
+				game.UndoMove()
+			}
+
+			if len(game.HashHistory) != 0 || game.ToFEN() != test.fen {
+				t.Errorf("expected undo to restore the history, got %v (%d)", game.ToFEN(), len(game.HashHistory))
+			}
+		})
+	}
+
+	t.Run("Fifty Moves", func(t *testing.T) {
+		game, err := FromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 100 80")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !game.CanClaimDraw() {
+			t.Error("expected the fifty-move rule to allow a claim")
+		}
+	})
+}
+
+func TestIsInsufficientMaterial(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"Start":                     {fen: START_POSITION, expected: false},
+		"Bare Kings":                {fen: "8/8/3k4/8/8/3K4/8/8 w - - 0 1", expected: true},
+		"Knight":                    {fen: "8/8/3k4/8/8/3K4/3N4/8 w - - 0 1", expected: true},
+		"Bishop":                    {fen: "8/8/3k4/8/8/3K4/3b4/8 w - - 0 1", expected: true},
+		"Same Colored Bishops":      {fen: "8/8/3k1b2/8/8/3K4/3B4/8 w - - 0 1", expected: true},
+		"Many Same Colored Bishops": {fen: "1B6/8/3k4/8/8/3K4/3B4/6B1 w - - 0 1", expected: true},
+		"Opposite Colored Bishops":  {fen: "8/8/3k2b1/8/8/3K4/3B4/8 w - - 0 1", expected: false},
+		"Two Knights":               {fen: "8/8/3k4/8/8/3K4/3NN3/8 w - - 0 1", expected: false},
+		"Knight Against Knight":     {fen: "8/8/3k4/3n4/8/3K4/3N4/8 w - - 0 1", expected: false},
+		"Knight And Bishop":         {fen: "8/8/3k4/8/8/3K4/3NB3/8 w - - 0 1", expected: false},
+		"Pawn":                      {fen: "8/8/3k4/8/8/3K4/3P4/8 w - - 0 1", expected: false},
+		"Rook":                      {fen: "8/8/3k4/8/8/3K4/3r4/8 w - - 0 1", expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if game.IsInsufficientMaterial() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, game.IsInsufficientMaterial())
+			}
+
+			if status := game.Status(); (status == InsufficientMaterial) != test.expected {
+				t.Errorf("expected status to reflect insufficient material, got %v", status)
+			}
+		})
+	}
+}
+
+func coordsAlgebra(coords []Coordinate) string {
+	result := []string{}
+	for _, coord := range coords {
+		result = append(result, coord.GetAlgebra())
+	}
+	return strings.Join(result, " ")
+}
+
+func TestAttackQueries(t *testing.T) {
+	tests := map[string]struct {
+		fen string
+		// Square to list the attackers of, and the expected results in square order
+		square    string
+		attackers string
+		checkers  string
+		// Pinned pieces of the active player
+		pinned string
+	}{
+		"Start": {
+			fen:       START_POSITION,
+			square:    "f3",
+			attackers: "g1 e2 g2",
+		},
+		"Both Colors": {
+			fen:       "4k3/8/8/3p4/8/2N5/8/R3K3 w - - 0 1",
+			square:    "d5",
+			attackers: "c3",
+		},
+		"Blocked Check": {
+			fen:       "4k3/8/8/8/1b6/8/3P4/4K3 w - - 0 1",
+			square:    "d2",
+			attackers: "e1 b4",
+			pinned:    "d2",
+		},
+		"Check": {
+			fen:       "4k3/8/8/8/1b6/8/8/4K3 w - - 0 1",
+			square:    "d2",
+			attackers: "e1 b4",
+			checkers:  "b4",
+		},
+		"Double Check": {
+			fen:       "4k3/8/8/8/8/7b/3n4/4RK2 w - - 0 1",
+			square:    "f1",
+			attackers: "e1 d2 h3",
+			checkers:  "d2 h3",
+		},
+		"Pins": {
+			fen:       "4r2k/8/8/1b6/8/3N4/4B3/r1N1K3 w - - 0 1",
+			square:    "e2",
+			attackers: "c1 e1 e8",
+			pinned:    "c1 e2",
+		},
+		"Two Blockers": {
+			fen:       "4r2k/8/8/4p3/8/8/4B3/4K3 w - - 0 1",
+			square:    "e5",
+			attackers: "e8",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			game, err := FromFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := coordsAlgebra(game.AttackersOf(CreateCoordAlgebra(test.square))); result != test.attackers {
+				t.Errorf("expected attackers of %v to be %q, got %q", test.square, test.attackers, result)
+			}
+
+			if result := coordsAlgebra(game.Checkers()); result != test.checkers {
+				t.Errorf("expected checkers %q, got %q", test.checkers, result)
+			}
+
+			if result := coordsAlgebra(game.PinnedPieces(game.Active)); result != test.pinned {
+				t.Errorf("expected pinned pieces %q, got %q", test.pinned, result)
+			}
+
+			if game.InCheck() != (test.checkers != "") {
+				t.Errorf("expected InCheck to agree with checkers %q", test.checkers)
+			}
+		})
+	}
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+	game, err := FromFEN("4k3/8/8/3p4/8/2N5/8/R3K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		square   string
+		by       Piece
+		expected bool
+	}{
+		"White Rook":   {square: "a8", by: White, expected: true},
+		"Black Pawn":   {square: "c4", by: Black, expected: true},
+		"Not By Black": {square: "a8", by: Black, expected: false},
+		"Pawn Push":    {square: "d4", by: Black, expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if result := game.IsSquareAttacked(CreateCoordAlgebra(test.square), test.by); result != test.expected {
+				t.Errorf("expected %v attacked by %v: %v, got %v", test.square, test.by, test.expected, result)
+			}
+		})
+	}
+}
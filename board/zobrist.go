@@ -0,0 +1,168 @@
+package board
+
+// Random keys used to build a Zobrist hash of a position,
+// a position's hash is the XOR of the keys for each of its features.
+var zobrist struct {
+	pieces    [2][6][64]uint64
+	black     uint64
+	castling  [2]castlingKeys
+	enPassant [8]uint64
+	// Chess960 rook files castling rights are for, when not in the corner
+	rookFiles [2][8]uint64
+}
+
+type castlingKeys struct {
+	QueenSide uint64
+	KingSide  uint64
+}
+
+func init() {
+	// Fixed seed so hashes are stable between runs
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		// xorshift64*
+		seed ^= seed >> 12
+		seed ^= seed << 25
+		seed ^= seed >> 27
+		return seed * 0x2545F4914F6CDD1D
+	}
+
+	for color := range zobrist.pieces {
+		for piece := range zobrist.pieces[color] {
+			for square := range zobrist.pieces[color][piece] {
+				zobrist.pieces[color][piece][square] = next()
+			}
+		}
+	}
+
+	zobrist.black = next()
+
+	for color := range zobrist.castling {
+		zobrist.castling[color] = castlingKeys{QueenSide: next(), KingSide: next()}
+	}
+
+	for col := range zobrist.enPassant {
+		zobrist.enPassant[col] = next()
+	}
+
+	for color := range zobrist.rookFiles {
+		for col := range zobrist.rookFiles[color] {
+			zobrist.rookFiles[color][col] = next()
+		}
+	}
+}
+
+func pieceKey(coord Coordinate, piece Piece) uint64 {
+	if piece == 0 {
+		return 0
+	}
+
+	row, col := coord.GetCoords()
+	color := 0
+	if piece.GetColor() == Black {
+		color = 1
+	}
+
+	var index int
+	switch piece.GetType() {
+	case Pawn:
+		index = 0
+	case Knight:
+		index = 1
+	case Bishop:
+		index = 2
+	case Rook:
+		index = 3
+	case Queen:
+		index = 4
+	case King:
+		index = 5
+	}
+
+	return zobrist.pieces[color][index][int(row)*8+int(col)]
+}
+
+// The key for a color's castling rights. In Chess960 a right is for a
+// particular rook, so the rook's file is included when it isn't in the corner.
+func (board Game) castlingKey(color int, castling Castling) uint64 {
+	keys, files := zobrist.castling[color], board.rookFiles[color]
+
+	var key uint64
+	if castling.QueenSide {
+		key ^= keys.QueenSide
+		if files[0] != 0 {
+			key ^= zobrist.rookFiles[color][files[0]]
+		}
+	}
+
+	if castling.KingSide {
+		key ^= keys.KingSide
+		if files[1] != 7 {
+			key ^= zobrist.rookFiles[color][files[1]]
+		}
+	}
+
+	return key
+}
+
+// The part of the hash that is not tied to pieces:
+// castling rights and the en passant file.
+// The en passant file is only included when the capture is possible,
+// so that positions with the same moves available hash the same.
+func (board Game) stateHash() uint64 {
+	key := board.castlingKey(0, board.WhiteCastling) ^ board.castlingKey(1, board.BlackCastling)
+
+	if board.canCaptureEnPassant() {
+		_, col := board.EnPassant.GetCoords()
+		key ^= zobrist.enPassant[col]
+	}
+
+	return key
+}
+
+// Computes the hash of the position from scratch
+func (board Game) computeHash() uint64 {
+	var key uint64
+
+	for row := range board.Board {
+		for col, piece := range board.Board[row] {
+			key ^= pieceKey(CreateCoordInt(row, col), piece)
+		}
+	}
+
+	if board.Active == Black {
+		key ^= zobrist.black
+	}
+
+	return key ^ board.stateHash()
+}
+
+// Returns the 64-bit Zobrist hash of the current position,
+// which is kept up to date by MakeMove, UndoMove, Set and Move.
+func (board Game) Hash() uint64 {
+	return board.hash
+}
+
+// Sets the piece at coord, updating the hash accordingly
+func (board *Game) put(coord Coordinate, piece Piece) {
+	board.hash ^= pieceKey(coord, board.Get(coord)) ^ pieceKey(coord, piece)
+	board.setPiece(coord, piece)
+}
+
+// Whether a pawn of the side to move stands beside the pawn that just double pushed
+func (board Game) canCaptureEnPassant() bool {
+	if board.EnPassant == nil {
+		return false
+	}
+
+	row, col := board.EnPassant.GetCoords()
+	if board.Active == White {
+		row--
+	} else {
+		row++
+	}
+
+	pawn := Pawn | board.Active
+	return (col > 0 && board.Get(CreateCoordByte(row, col-1)) == pawn) ||
+		(col < 7 && board.Get(CreateCoordByte(row, col+1)) == pawn)
+}
@@ -0,0 +1,153 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	t.Run("FromFEN", func(t *testing.T) {
+		for name, test := range getPerfData() {
+			t.Run(name, func(t *testing.T) {
+				game, err := FromFEN(test.FEN)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if game.Hash() != game.computeHash() {
+					t.Errorf("expected hash %x, got %x", game.computeHash(), game.Hash())
+				}
+			})
+		}
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		fens := []string{
+			START_POSITION,
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1",
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3",
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3",
+			// The same right, but for a different rook
+			"4k3/8/8/8/8/8/8/RR2K3 w A - 0 1",
+			"4k3/8/8/8/8/8/8/RR2K3 w B - 0 1",
+		}
+
+		seen := map[uint64]string{}
+		for _, fen := range fens {
+			game, err := FromFEN(fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if other, ok := seen[game.Hash()]; ok {
+				t.Errorf("%v and %v share hash %x", fen, other, game.Hash())
+			}
+			seen[game.Hash()] = fen
+		}
+	})
+
+	t.Run("Transposition", func(t *testing.T) {
+		first, err := FromFEN(START_POSITION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := FromFEN(START_POSITION)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, move := range [][2]string{{"g1", "f3"}, {"g8", "f6"}, {"b1", "c3"}} {
+			first.MakeMove(first.CreateMoveStr(move[0], move[1]))
+		}
+		for _, move := range [][2]string{{"b1", "c3"}, {"g8", "f6"}, {"g1", "f3"}} {
+			second.MakeMove(second.CreateMoveStr(move[0], move[1]))
+		}
+
+		if first.Hash() != second.Hash() {
+			t.Errorf("transposed positions have different hashes, %x and %x", first.Hash(), second.Hash())
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		game, err := FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Removing the d4 pawn also takes away the en passant capture
+		game.Set(CreateCoordAlgebra("d4"), 0)
+		game.Move(CreateCoordAlgebra("g1"), CreateCoordAlgebra("f3"))
+
+		if game.Hash() != game.computeHash() {
+			t.Errorf("expected hash %x after Set and Move, got %x", game.computeHash(), game.Hash())
+		}
+	})
+
+	t.Run("Uncapturable En Passant", func(t *testing.T) {
+		game, err := FromFEN(START_POSITION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.MakeMove(game.CreateMoveStr("e2", "e4"))
+
+		expected := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+		if game.ToFEN() != expected {
+			t.Errorf("expected %v, got %v", expected, game.ToFEN())
+		}
+
+		// No black pawn can take on e3, so the square doesn't change the position
+		without, err := FromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if game.Hash() != without.Hash() {
+			t.Errorf("expected uncapturable en passant square to be left out of the hash")
+		}
+	})
+}
+
+// Every move (and its undo) should keep the incremental hash
+// equal to the hash computed from scratch
+func TestIncrementalHash(t *testing.T) {
+	for name, test := range getPerfData() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			game, err := FromFEN(test.FEN)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkHash(t, game, 3, []string{})
+		})
+	}
+}
+
+func checkHash(t *testing.T, game *Game, depth int, line []string) {
+	if depth == 0 {
+		return
+	}
+
+	before := game.Hash()
+
+	for _, move := range game.GetMoves() {
+		game.MakeMove(move)
+		line := append(line, move.String())
+
+		if game.Hash() != game.computeHash() {
+			t.Fatalf("incremental hash diverged after %v, expected %x, got %x",
+				strings.Join(line, " "), game.computeHash(), game.Hash())
+		}
+
+		checkHash(t, game, depth-1, line)
+		game.UndoMove()
+
+		if game.Hash() != before {
+			t.Fatalf("undoing %v did not restore hash, expected %x, got %x",
+				strings.Join(line, " "), before, game.Hash())
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/perft"
+	"github.com/msws/chess/tt"
+)
+
+var (
+	// Returned by run when the divide does not match the reference
+	errMismatch = errors.New("divide does not match reference")
+
+	// Returned by run when a position in the suite fails
+	errSuiteFailed = errors.New("perft suite failed")
+)
+
+type options struct {
+	fen       string
+	depth     int
+	divide    bool
+	details   bool
+	moves     string
+	hashMB    int
+	workers   int
+	reference string
+
+	// EPD perft suite to check, with depth limiting
+	// its counts only when given explicitly
+	suite    string
+	maxDepth int
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	opts, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	if opts.suite != "" {
+		return runSuite(opts, out)
+	}
+
+	game, err := board.FromFENLenient(opts.fen)
+	if err != nil {
+		return err
+	}
+
+	if opts.moves != "" {
+		for _, str := range strings.Split(opts.moves, ",") {
+			move, err := game.ParseUCIMove(strings.TrimSpace(str))
+			if err != nil {
+				return err
+			}
+			game.MakeMove(move)
+		}
+	}
+
+	if opts.details {
+		start := time.Now()
+		counts := game.PerftDetailed(opts.depth)
+
+		fmt.Fprintf(out, "Nodes: %d\n", counts.Nodes)
+		fmt.Fprintf(out, "Captures: %d\n", counts.Captures)
+		fmt.Fprintf(out, "En passant: %d\n", counts.EnPassant)
+		fmt.Fprintf(out, "Castles: %d\n", counts.Castles)
+		fmt.Fprintf(out, "Promotions: %d\n", counts.Promotions)
+		fmt.Fprintf(out, "Checks: %d\n", counts.Checks)
+		fmt.Fprintf(out, "Discovery checks: %d\n", counts.DiscoveryChecks)
+		fmt.Fprintf(out, "Double checks: %d\n", counts.DoubleChecks)
+		fmt.Fprintf(out, "Checkmates: %d\n", counts.Checkmates)
+		fmt.Fprintf(out, "Time: %v\n", time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+
+	table := newTable(opts)
+
+	start := time.Now()
+	counts := divide(game, opts.depth, opts.workers, table)
+	elapsed := time.Since(start)
+
+	total := 0
+	for _, nodes := range counts {
+		total += nodes
+	}
+
+	if opts.divide || opts.reference != "" {
+		for _, move := range sortedMoves(counts) {
+			fmt.Fprintf(out, "%s: %d\n", move, counts[move])
+		}
+		fmt.Fprintln(out)
+	}
+
+	nps := int64(total)
+	if elapsed > 0 {
+		nps = int64(float64(total) / elapsed.Seconds())
+	}
+
+	fmt.Fprintf(out, "Nodes: %d\n", total)
+	fmt.Fprintf(out, "Time: %v\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(out, "NPS: %d\n", nps)
+
+	if opts.reference == "" {
+		return nil
+	}
+
+	file, err := os.Open(opts.reference)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reference, err := parseDivide(file)
+	if err != nil {
+		return fmt.Errorf("failed to read reference: %w", err)
+	}
+
+	differences := compareDivide(counts, reference)
+	if len(differences) == 0 {
+		fmt.Fprintln(out, "Divide matches reference")
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	for _, difference := range differences {
+		fmt.Fprintln(out, difference)
+	}
+
+	return errMismatch
+}
+
+func parseFlags(args []string) (options, error) {
+	opts := options{}
+	flags := flag.NewFlagSet("perft", flag.ContinueOnError)
+
+	flags.StringVar(&opts.fen, "fen", board.START_POSITION, "position to count from, X-FEN or Shredder-FEN for Chess960")
+	flags.IntVar(&opts.depth, "depth", 5, "number of plies to count")
+	flags.BoolVar(&opts.divide, "divide", false, "print the node count below each root move")
+	flags.BoolVar(&opts.details, "details", false, "break the count down into captures, checks, etc. (uncached and single threaded)")
+	flags.StringVar(&opts.moves, "moves", "", "comma separated UCI moves to play before counting, e.g. e2e4,e7e5")
+	flags.IntVar(&opts.hashMB, "hash-mb", 64, "transposition table size in megabytes, 0 to disable")
+	flags.IntVar(&opts.workers, "workers", runtime.GOMAXPROCS(0), "number of goroutines to count with")
+	flags.StringVar(&opts.reference, "reference", "", "divide file (\"e2e4: 20\" per line) to compare against, implies --divide")
+	flags.StringVar(&opts.suite, "suite", "", "EPD perft suite (\"<fen> ;D1 20 ;D2 400\" per line) to check")
+
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "depth" {
+			opts.maxDepth = opts.depth
+		}
+	})
+
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	if opts.depth < 1 {
+		return opts, fmt.Errorf("depth must be at least 1, got %d", opts.depth)
+	}
+
+	if opts.workers < 1 {
+		opts.workers = 1
+	}
+
+	return opts, nil
+}
+
+// Checks every position in the suite, printing a line per position
+func runSuite(opts options, out io.Writer) error {
+	positions, err := perft.LoadSuite(opts.suite)
+	if err != nil {
+		return err
+	}
+
+	table := newTable(opts)
+
+	start := time.Now()
+	passed := 0
+
+	for _, position := range positions {
+		result := perft.Run(position, opts.maxDepth, table)
+		if result.Passed() {
+			passed++
+		}
+
+		fmt.Fprintln(out, result)
+	}
+
+	fmt.Fprintf(out, "\n%d/%d passed in %v\n", passed, len(positions), time.Since(start).Round(time.Millisecond))
+
+	if passed != len(positions) {
+		return errSuiteFailed
+	}
+
+	return nil
+}
+
+// Counts the nodes below each legal root move, keyed by the move in UCI notation
+// (with castling as king takes rook in Chess960)
+func divide(game *board.Game, depth, workers int, table *tt.Table[board.Move]) map[string]int {
+	counts := map[string]int{}
+
+	for _, move := range game.GetMoves() {
+		game.MakeMove(move)
+		nodes := game.PerftParallel(depth-1, workers, table)
+		game.UndoMove()
+
+		key := move.UCI()
+		if game.Chess960 {
+			key = move.UCIChess960()
+		}
+		counts[key] = nodes
+	}
+
+	return counts
+}
+
+// Reads a divide in the "move: nodes" format printed by most engines,
+// ignoring any lines that are not in that format (such as totals)
+func parseDivide(in io.Reader) (map[string]int, error) {
+	result := map[string]int{}
+	scanner := bufio.NewScanner(in)
+
+	for scanner.Scan() {
+		move, count, found := strings.Cut(scanner.Text(), ":")
+		move = strings.TrimSpace(move)
+		if !found || len(move) < 4 || len(move) > 5 {
+			continue
+		}
+
+		nodes, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			continue
+		}
+
+		result[move] = nodes
+	}
+
+	return result, scanner.Err()
+}
+
+// Describes every root move whose count differs from the reference,
+// including moves only one of the two generated
+func compareDivide(counts, reference map[string]int) []string {
+	differences := []string{}
+	all := map[string]int{}
+	for move := range counts {
+		all[move] = 0
+	}
+	for move := range reference {
+		all[move] = 0
+	}
+
+	for _, move := range sortedMoves(all) {
+		ours, generated := counts[move]
+		theirs, expected := reference[move]
+
+		switch {
+		case !expected:
+			differences = append(differences, fmt.Sprintf("%s: illegal move generated", move))
+		case !generated:
+			differences = append(differences, fmt.Sprintf("%s: legal move not generated", move))
+		case ours != theirs:
+			differences = append(differences, fmt.Sprintf("%s: expected %d, got %d", move, theirs, ours))
+		}
+	}
+
+	return differences
+}
+
+func sortedMoves(counts map[string]int) []string {
+	moves := make([]string, 0, len(counts))
+	for move := range counts {
+		moves = append(moves, move)
+	}
+
+	sort.Strings(moves)
+	return moves
+}
+
+// Allocates the table sized by -hash-mb, or nil when caching is disabled
+func newTable(opts options) *tt.Table[board.Move] {
+	if opts.hashMB <= 0 {
+		return nil
+	}
+
+	return tt.New[board.Move](opts.hashMB)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		contains []string
+		excludes []string
+	}{
+		"Total": {
+			args:     []string{"--depth", "3"},
+			contains: []string{"Nodes: 8902\n", "Time: ", "NPS: "},
+			excludes: []string{"e2e4: "},
+		},
+		"Divide": {
+			args:     []string{"--depth", "2", "--divide"},
+			contains: []string{"e2e4: 20\n", "g1f3: 20\n", "Nodes: 400\n"},
+		},
+		"FEN": {
+			args:     []string{"--fen", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", "--depth", "3"},
+			contains: []string{"Nodes: 2812\n"},
+		},
+		"Moves": {
+			args:     []string{"--moves", "e2e4,e7e5", "--depth", "1", "--divide"},
+			contains: []string{"e1e2: 1\n", "Nodes: 29\n"},
+		},
+		"Details": {
+			args:     []string{"--depth", "3", "--details"},
+			contains: []string{"Nodes: 8902\n", "Captures: 34\n", "Checks: 12\n", "Checkmates: 0\n"},
+		},
+		"Without Table": {
+			args:     []string{"--depth", "3", "--hash-mb", "0", "--workers", "1"},
+			contains: []string{"Nodes: 8902\n"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(test.args, &out); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range test.contains {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain %q, got %q", expected, out.String())
+				}
+			}
+
+			for _, unexpected := range test.excludes {
+				if strings.Contains(out.String(), unexpected) {
+					t.Errorf("expected output not to contain %q, got %q", unexpected, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := map[string][]string{
+		"Bad Depth":    {"--depth", "0"},
+		"Bad FEN":      {"--fen", "not a fen"},
+		"Bad Move":     {"--moves", "e2e5"},
+		"Extra Args":   {"5"},
+		"Unknown Flag": {"--speed", "fast"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := run(args, &bytes.Buffer{}); err == nil {
+				t.Errorf("expected error for %v", args)
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	dir := t.TempDir()
+	reference := "a2a3: 20\na2a4: 20\nb1a3: 20\nb1c3: 20\nb2b3: 20\nb2b4: 20\nc2c3: 20\nc2c4: 20\n" +
+		"d2d3: 20\nd2d4: 20\ne2e3: 20\ne2e4: 20\nf2f3: 20\nf2f4: 20\ng1f3: 20\ng1h3: 20\n" +
+		"g2g3: 20\ng2g4: 20\nh2h3: 20\nh2h4: 20\n\nNodes searched: 400\n"
+
+	t.Run("Match", func(t *testing.T) {
+		path := filepath.Join(dir, "match.txt")
+		if err := os.WriteFile(path, []byte(reference), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		if err := run([]string{"--depth", "2", "--reference", path}, &out); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), "Divide matches reference") {
+			t.Errorf("expected a match, got %q", out.String())
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		broken := strings.Replace(reference, "e2e4: 20", "e2e4: 21", 1)
+		broken = strings.Replace(broken, "h2h4: 20\n", "", 1)
+		broken += "e1e2: 20\n"
+
+		path := filepath.Join(dir, "mismatch.txt")
+		if err := os.WriteFile(path, []byte(broken), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		err := run([]string{"--depth", "2", "--reference", path}, &out)
+		if !errors.Is(err, errMismatch) {
+			t.Fatalf("expected a mismatch, got %v", err)
+		}
+
+		for _, expected := range []string{
+			"e1e2: legal move not generated\n",
+			"e2e4: expected 21, got 20\n",
+			"h2h4: illegal move generated\n",
+		} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected output to contain %q, got %q", expected, out.String())
+			}
+		}
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		if err := run([]string{"--depth", "1", "--reference", filepath.Join(dir, "missing.txt")}, &bytes.Buffer{}); err == nil {
+			t.Error("expected error for a missing reference file")
+		}
+	})
+}
+
+func TestSuite(t *testing.T) {
+	dir := t.TempDir()
+	suite := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D1 20 ;D2 400 ;D3 8902\n" +
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - ;D1 14 ;D2 191 ;D3 2813\n"
+
+	path := filepath.Join(dir, "suite.epd")
+	if err := os.WriteFile(path, []byte(suite), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Failure", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run([]string{"--suite", path}, &out)
+		if !errors.Is(err, errSuiteFailed) {
+			t.Fatalf("expected the suite to fail, got %v", err)
+		}
+
+		for _, expected := range []string{
+			"PASS rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1\n",
+			"FAIL 8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1: depth 3 expected 2813, got 2812\n",
+			"1/2 passed",
+		} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected output to contain %q, got %q", expected, out.String())
+			}
+		}
+	})
+
+	t.Run("Limited Depth", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{"--suite", path, "--depth", "2"}, &out); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), "2/2 passed") {
+			t.Errorf("expected both positions to pass, got %q", out.String())
+		}
+	})
+}
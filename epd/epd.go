@@ -0,0 +1,408 @@
+package epd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/msws/chess/board"
+)
+
+// Returned by the Record accessors when the operation is not present
+var ErrNoOperation = errors.New("operation not present")
+
+// A position from an EPD file along with its operations
+type Record struct {
+	Position   *board.Game
+	Operations Operations
+}
+
+// Operands keyed by opcode, remembering the order the opcodes were added in
+type Operations struct {
+	opcodes  []string
+	operands map[string][]string
+}
+
+// Sets the operands of opcode, keeping its place if already present
+func (ops *Operations) Set(opcode string, operands ...string) {
+	if ops.operands == nil {
+		ops.operands = map[string][]string{}
+	}
+
+	if _, ok := ops.operands[opcode]; !ok {
+		ops.opcodes = append(ops.opcodes, opcode)
+	}
+
+	ops.operands[opcode] = operands
+}
+
+func (ops Operations) Get(opcode string) ([]string, bool) {
+	operands, ok := ops.operands[opcode]
+	return operands, ok
+}
+
+func (ops *Operations) Delete(opcode string) {
+	if _, ok := ops.operands[opcode]; !ok {
+		return
+	}
+
+	delete(ops.operands, opcode)
+	for i, existing := range ops.opcodes {
+		if existing == opcode {
+			ops.opcodes = append(ops.opcodes[:i], ops.opcodes[i+1:]...)
+			break
+		}
+	}
+}
+
+// The opcodes in the order they were added
+func (ops Operations) Opcodes() []string {
+	return append([]string{}, ops.opcodes...)
+}
+
+func (ops Operations) Len() int {
+	return len(ops.opcodes)
+}
+
+// The known node count at Depth plies, from a Dn operation
+type PerftCount struct {
+	Depth int
+	Nodes int
+}
+
+// The position's identifier, from the id operation
+func (record Record) ID() string {
+	return record.single("id")
+}
+
+// The nth comment (c0 to c9)
+func (record Record) Comment(n int) string {
+	return record.single(fmt.Sprintf("c%d", n))
+}
+
+func (record Record) single(opcode string) string {
+	operands, _ := record.Operations.Get(opcode)
+	return strings.Join(operands, " ")
+}
+
+// The best moves (bm) to play from the position
+func (record Record) BestMoves() ([]board.Move, error) {
+	return record.moves("bm")
+}
+
+// The moves to avoid (am) from the position
+func (record Record) AvoidMoves() ([]board.Move, error) {
+	return record.moves("am")
+}
+
+func (record Record) moves(opcode string) ([]board.Move, error) {
+	operands, ok := record.Operations.Get(opcode)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", opcode, ErrNoOperation)
+	}
+
+	result := []board.Move{}
+	for _, san := range operands {
+		move, err := record.Position.ParseSAN(san)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opcode, err)
+		}
+
+		result = append(result, move)
+	}
+
+	return result, nil
+}
+
+// The predicted variation (pv), each move played from the position after the last
+func (record Record) PV() ([]board.Move, error) {
+	operands, ok := record.Operations.Get("pv")
+	if !ok {
+		return nil, fmt.Errorf("pv: %w", ErrNoOperation)
+	}
+
+	game := record.Position.Clone()
+	result := []board.Move{}
+	for _, san := range operands {
+		move, err := game.ParseSAN(san)
+		if err != nil {
+			return nil, fmt.Errorf("pv: %w", err)
+		}
+
+		game.MakeMove(move)
+		result = append(result, move)
+	}
+
+	return result, nil
+}
+
+// The depth the position was analysed to (acd)
+func (record Record) Depth() (int, error) {
+	return record.integer("acd")
+}
+
+// The evaluation of the position in centipawns (ce),
+// from the perspective of the side to move
+func (record Record) Eval() (int, error) {
+	return record.integer("ce")
+}
+
+func (record Record) integer(opcode string) (int, error) {
+	operands, ok := record.Operations.Get(opcode)
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", opcode, ErrNoOperation)
+	}
+
+	if len(operands) != 1 {
+		return 0, fmt.Errorf("%s: expected 1 operand, got %d", opcode, len(operands))
+	}
+
+	value, err := strconv.Atoi(operands[0])
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid integer %q", opcode, operands[0])
+	}
+
+	return value, nil
+}
+
+// The known perft node counts (D1, D2, ...), sorted by depth
+func (record Record) PerftCounts() ([]PerftCount, error) {
+	result := []PerftCount{}
+
+	for _, opcode := range record.Operations.opcodes {
+		depth, ok := perftDepth(opcode)
+		if !ok {
+			continue
+		}
+
+		if depth < 1 {
+			return nil, fmt.Errorf("invalid depth %q", opcode)
+		}
+
+		nodes, err := record.integer(opcode)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, PerftCount{depth, nodes})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Depth < result[j].Depth
+	})
+
+	return result, nil
+}
+
+// The depth of a Dn opcode
+func perftDepth(opcode string) (int, bool) {
+	if len(opcode) < 2 || opcode[0] != 'D' {
+		return 0, false
+	}
+
+	depth, err := strconv.Atoi(opcode[1:])
+	return depth, err == nil
+}
+
+// Sets the best moves (bm), in SAN from the record's position
+func (record *Record) SetBestMoves(moves ...board.Move) {
+	record.setMoves("bm", moves)
+}
+
+// Sets the moves to avoid (am), in SAN from the record's position
+func (record *Record) SetAvoidMoves(moves ...board.Move) {
+	record.setMoves("am", moves)
+}
+
+func (record *Record) setMoves(opcode string, moves []board.Move) {
+	operands := []string{}
+	for _, move := range moves {
+		operands = append(operands, move.GetAlgebra(record.Position))
+	}
+
+	record.Operations.Set(opcode, operands...)
+}
+
+// Sets the predicted variation (pv), with each move played from the position after the last
+func (record *Record) SetPV(moves ...board.Move) {
+	game := record.Position.Clone()
+	operands := []string{}
+	for _, move := range moves {
+		operands = append(operands, move.GetAlgebra(game))
+		game.MakeMove(move)
+	}
+
+	record.Operations.Set("pv", operands...)
+}
+
+// Reads EPD records, one per line.
+// Blank lines and lines starting with # are ignored.
+func Parse(r io.Reader) ([]Record, error) {
+	result := []Record{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		record, err := ParseRecord(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		result = append(result, record)
+	}
+
+	return result, scanner.Err()
+}
+
+// Reads the EPD file at path, see Parse
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parses a single EPD record: the first four fields of a FEN followed
+// by operations, each an opcode and its operands terminated by a semicolon
+// (e.g. `bm Nf3 e4; id "example";`).
+// The move clocks are taken from the hmvc and fmvn operations if present.
+// For compatibility with perft suites, the clocks may also follow the FEN
+// as plain numbers, operations may start with a semicolon,
+// and the final semicolon may be omitted.
+func ParseRecord(line string) (Record, error) {
+	record := Record{}
+	tokens, err := tokenize(line)
+	if err != nil {
+		return record, err
+	}
+
+	fields := []string{}
+	for len(fields) < 4 && len(tokens) > 0 && !tokens[0].quoted && tokens[0].text != ";" {
+		fields = append(fields, tokens[0].text)
+		tokens = tokens[1:]
+	}
+
+	if len(fields) != 4 {
+		return record, fmt.Errorf("expected 4 FEN fields, got %d", len(fields))
+	}
+
+	clocks := []string{"0", "1"}
+	for i := 0; i < len(clocks) && len(tokens) > 0 && isNumber(tokens[0]); i++ {
+		clocks[i] = tokens[0].text
+		tokens = tokens[1:]
+	}
+
+	for len(tokens) > 0 {
+		if tokens[0].text == ";" && !tokens[0].quoted {
+			tokens = tokens[1:]
+			continue
+		}
+
+		opcode := tokens[0]
+		if opcode.quoted || !isOpcode(opcode.text) {
+			return record, fmt.Errorf("invalid opcode %q", opcode.text)
+		}
+
+		operands := []string{}
+		for tokens = tokens[1:]; len(tokens) > 0; tokens = tokens[1:] {
+			if tokens[0].text == ";" && !tokens[0].quoted {
+				break
+			}
+
+			operands = append(operands, tokens[0].text)
+		}
+
+		record.Operations.Set(opcode.text, operands...)
+	}
+
+	for i, opcode := range []string{"hmvc", "fmvn"} {
+		if operands, ok := record.Operations.Get(opcode); ok && len(operands) == 1 {
+			clocks[i] = operands[0]
+		}
+	}
+
+	position, err := board.FromFENLenient(strings.Join(append(fields, clocks...), " "))
+	if err != nil {
+		return record, err
+	}
+
+	record.Position = position
+	return record, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// Splits a record into words, quoted strings and semicolons
+func tokenize(line string) ([]token, error) {
+	result := []token{}
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); {
+		switch c := runes[i]; {
+		case unicode.IsSpace(c):
+			i++
+		case c == ';':
+			result = append(result, token{text: ";"})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+
+			result = append(result, token{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != ';' {
+				end++
+			}
+
+			result = append(result, token{text: string(runes[i:end])})
+			i = end
+		}
+	}
+
+	return result, nil
+}
+
+func isNumber(tok token) bool {
+	_, err := strconv.Atoi(tok.text)
+	return !tok.quoted && err == nil
+}
+
+// Opcodes start with a letter and are made up of at most
+// 15 letters, digits and underscores
+func isOpcode(str string) bool {
+	if len(str) == 0 || len(str) > 15 || !unicode.IsLetter(rune(str[0])) {
+		return false
+	}
+
+	for _, c := range str {
+		if c > unicode.MaxASCII || (!unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_') {
+			return false
+		}
+	}
+
+	return true
+}
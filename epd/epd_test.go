@@ -0,0 +1,184 @@
+package epd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const wac001 = `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`
+
+func TestParseRecord(t *testing.T) {
+	record, err := ParseRecord(wac001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fen := record.Position.ToFEN(); fen != "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1" {
+		t.Errorf("unexpected position %v", fen)
+	}
+
+	if record.ID() != "WAC.001" {
+		t.Errorf("expected id WAC.001, got %q", record.ID())
+	}
+
+	if opcodes := strings.Join(record.Operations.Opcodes(), ","); opcodes != "bm,id" {
+		t.Errorf("expected opcodes in order bm,id, got %v", opcodes)
+	}
+
+	moves, err := record.BestMoves()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(moves) != 1 || moves[0].UCI() != "g3g6" {
+		t.Errorf("expected best move g3g6, got %v", moves)
+	}
+
+	if _, err := record.AvoidMoves(); !errors.Is(err, ErrNoOperation) {
+		t.Errorf("expected ErrNoOperation, got %v", err)
+	}
+}
+
+func TestParseRecordOperations(t *testing.T) {
+	record, err := ParseRecord(`rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ` +
+		`c0 "opening; start"; c1 "second"; acd 12; ce -35; pv e4 e5 Nf3; am f3 g4; hmvc 4; fmvn 9;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Comment(0) != "opening; start" || record.Comment(1) != "second" {
+		t.Errorf("unexpected comments %q, %q", record.Comment(0), record.Comment(1))
+	}
+
+	if depth, err := record.Depth(); err != nil || depth != 12 {
+		t.Errorf("expected depth 12, got %v (%v)", depth, err)
+	}
+
+	if eval, err := record.Eval(); err != nil || eval != -35 {
+		t.Errorf("expected eval -35, got %v (%v)", eval, err)
+	}
+
+	pv, err := record.PV()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pv) != 3 || pv[2].UCI() != "g1f3" {
+		t.Errorf("unexpected pv %v", pv)
+	}
+
+	if avoid, err := record.AvoidMoves(); err != nil || len(avoid) != 2 {
+		t.Errorf("expected 2 moves to avoid, got %v (%v)", avoid, err)
+	}
+
+	if record.Position.HalfMoves != 4 || record.Position.FullMoves != 9 {
+		t.Errorf("expected clocks from hmvc and fmvn, got %v", record.Position.ToFEN())
+	}
+}
+
+func TestParseRecordPerft(t *testing.T) {
+	tests := map[string]string{
+		"Clocks":       "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1 ;D2 191 ;D1 14",
+		"No Clocks":    "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - ;D1 14 ;D2 191",
+		"Standard EPD": "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - D1 14; D2 191;",
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			record, err := ParseRecord(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			counts, err := record.PerftCounts()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(counts) != 2 || counts[0] != (PerftCount{1, 14}) || counts[1] != (PerftCount{2, 191}) {
+				t.Errorf("expected counts sorted by depth, got %v", counts)
+			}
+		})
+	}
+}
+
+func TestParseRecordErrors(t *testing.T) {
+	tests := map[string]string{
+		"Missing Fields":      "8/8/8/8/8/8/8/K6k w -",
+		"Bad FEN":             "8/8/8/8/8/8/8/K5k w - - bm Ka2;",
+		"Bad Opcode":          "8/8/8/8/8/8/8/K6k w - - 3x e4;",
+		"Quoted Opcode":       `8/8/8/8/8/8/8/K6k w - - "id" a;`,
+		"Unterminated String": `8/8/8/8/8/8/8/K6k w - - id "WAC.001;`,
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseRecord(line); err == nil {
+				t.Errorf("expected error for %q", line)
+			}
+		})
+	}
+
+	t.Run("Illegal Best Move", func(t *testing.T) {
+		record, err := ParseRecord("8/8/8/8/8/8/8/K6k w - - bm Kc3;")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := record.BestMoves(); err == nil {
+			t.Error("expected error for an illegal best move")
+		}
+	})
+
+	t.Run("Bad Count", func(t *testing.T) {
+		record, err := ParseRecord("8/8/8/8/8/8/8/K6k w - - D0 3;")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := record.PerftCounts(); err == nil {
+			t.Error("expected error for a zero depth")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	input := "# Win at Chess\n" + wac001 + "\n\n" +
+		`r1b1kb1r/3q1ppp/pBp1pn2/8/Np3P2/5B2/PPP3PP/R2Q1RK1 w kq - bm Bxc6; id "WAC.003";` + "\n"
+
+	records, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[1].ID() != "WAC.003" {
+		t.Errorf("expected 2 records, got %v", records)
+	}
+
+	if _, err := Parse(strings.NewReader(wac001 + "\nnot a record\n")); err == nil || !strings.HasPrefix(err.Error(), "line 2") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	ops := Operations{}
+	ops.Set("id", "a")
+	ops.Set("bm", "e4")
+	ops.Set("c0", "comment")
+	ops.Set("id", "b")
+	ops.Delete("bm")
+	ops.Delete("missing")
+
+	if opcodes := strings.Join(ops.Opcodes(), ","); opcodes != "id,c0" {
+		t.Errorf("expected id,c0, got %v", opcodes)
+	}
+
+	if operands, ok := ops.Get("id"); !ok || len(operands) != 1 || operands[0] != "b" {
+		t.Errorf("expected id to be replaced in place, got %v", operands)
+	}
+
+	if _, ok := ops.Get("bm"); ok || ops.Len() != 2 {
+		t.Errorf("expected bm to be deleted")
+	}
+}
@@ -0,0 +1,50 @@
+package epd
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Opcodes whose operand is a string, which is always quoted
+var stringOpcode = regexp.MustCompile(`^(id|c[0-9])$`)
+
+// Formats the record as a single EPD line (without a trailing newline),
+// writing the operations in the order they were added
+func Format(record Record) string {
+	var sb strings.Builder
+	fields := strings.Fields(record.Position.ToFEN())
+	sb.WriteString(strings.Join(fields[:4], " "))
+
+	for _, opcode := range record.Operations.opcodes {
+		sb.WriteRune(' ')
+		sb.WriteString(opcode)
+
+		for _, operand := range record.Operations.operands[opcode] {
+			sb.WriteRune(' ')
+			if stringOpcode.MatchString(opcode) || operand == "" || strings.ContainsAny(operand, " \t;") {
+				sb.WriteString(`"` + operand + `"`)
+			} else {
+				sb.WriteString(operand)
+			}
+		}
+
+		sb.WriteRune(';')
+	}
+
+	return sb.String()
+}
+
+// Writes each record on its own line
+func Write(w io.Writer, records []Record) error {
+	writer := bufio.NewWriter(w)
+
+	for _, record := range records {
+		if _, err := writer.WriteString(Format(record) + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
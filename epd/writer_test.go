@@ -0,0 +1,81 @@
+package epd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/msws/chess/board"
+)
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"WAC": {
+			input:    wac001,
+			expected: wac001,
+		},
+		"Perft Suite": {
+			input:    "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1 ;D1 14 ;D2 191",
+			expected: "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - D1 14; D2 191;",
+		},
+		"Quoting": {
+			input:    `8/8/8/8/8/8/8/K6k w - - c0 "a; b"; c1 single; pv Kb2 Kg7;`,
+			expected: `8/8/8/8/8/8/8/K6k w - - c0 "a; b"; c1 "single"; pv Kb2 Kg7;`,
+		},
+		"No Operations": {
+			input:    "8/8/8/8/8/8/8/K6k b - -",
+			expected: "8/8/8/8/8/8/8/K6k b - -",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			record, err := ParseRecord(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result := Format(record); result != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatMoves(t *testing.T) {
+	game, err := board.FromFEN(board.START_POSITION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e4, err := game.ParseSAN("e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nf3, err := game.ParseSAN("Nf3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := Record{Position: game}
+	record.Operations.Set("id", "start")
+	record.SetBestMoves(e4, nf3)
+	record.SetPV(e4)
+
+	expected := `rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - id "start"; bm e4 Nf3; pv e4;`
+	if result := Format(record); result != expected {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, []Record{record, record}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != expected+"\n"+expected+"\n" {
+		t.Errorf("expected one record per line, got %q", out.String())
+	}
+}
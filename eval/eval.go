@@ -0,0 +1,154 @@
+package eval
+
+import "github.com/msws/chess/board"
+
+// Game phase contributed by each piece type,
+// a board with all pieces present is at MaxPhase
+const MaxPhase = 24
+
+var phaseValues = map[board.Piece]int{
+	board.Knight: 1,
+	board.Bishop: 1,
+	board.Rook:   2,
+	board.Queen:  4,
+}
+
+var middlegameValues = map[board.Piece]int{
+	board.Pawn:   82,
+	board.Knight: 337,
+	board.Bishop: 365,
+	board.Rook:   477,
+	board.Queen:  1025,
+}
+
+var endgameValues = map[board.Piece]int{
+	board.Pawn:   94,
+	board.Knight: 281,
+	board.Bishop: 297,
+	board.Rook:   512,
+	board.Queen:  936,
+}
+
+// A middlegame and endgame score pair,
+// which are blended together based on the game phase.
+type Score struct {
+	Middlegame int
+	Endgame    int
+}
+
+func (score Score) Add(other Score) Score {
+	return Score{score.Middlegame + other.Middlegame, score.Endgame + other.Endgame}
+}
+
+func (score Score) Sub(other Score) Score {
+	return Score{score.Middlegame - other.Middlegame, score.Endgame - other.Endgame}
+}
+
+// Blends the middlegame and endgame scores,
+// phase ranges from 0 (endgame) to MaxPhase (opening)
+func (score Score) Taper(phase int) int {
+	return (score.Middlegame*phase + score.Endgame*(MaxPhase-phase)) / MaxPhase
+}
+
+// A Term is a single component of the evaluation,
+// scored from White's perspective.
+type Term func(game *board.Game) Score
+
+type Evaluator struct {
+	Terms []Term
+}
+
+// The evaluator used by Evaluate,
+// additional terms (pawn structure, king safety, mobility...)
+// can be appended to its Terms.
+var Default = Evaluator{
+	Terms: []Term{Material, PieceSquares},
+}
+
+// Evaluates game with the Default evaluator
+func Evaluate(game *board.Game) int {
+	return Default.Evaluate(game)
+}
+
+// Returns the score in centipawns from the perspective of the side to move
+func (evaluator Evaluator) Evaluate(game *board.Game) int {
+	total := Score{}
+	for _, term := range evaluator.Terms {
+		total = total.Add(term(game))
+	}
+
+	score := total.Taper(Phase(game))
+	if game.Active == board.Black {
+		return -score
+	}
+
+	return score
+}
+
+// Returns how far the game is from the endgame,
+// between 0 (bare kings and pawns) and MaxPhase.
+func Phase(game *board.Game) int {
+	phase := 0
+	forEachPiece(game, func(_ board.Coordinate, piece board.Piece) {
+		phase += phaseValues[piece.GetType()]
+	})
+
+	if phase > MaxPhase {
+		return MaxPhase
+	}
+
+	return phase
+}
+
+// Middlegame value of a piece in centipawns, ignoring its position
+func PieceValue(piece board.Piece) int {
+	return middlegameValues[piece.GetType()]
+}
+
+func Material(game *board.Game) Score {
+	score := Score{}
+	forEachPiece(game, func(_ board.Coordinate, piece board.Piece) {
+		value := Score{middlegameValues[piece.GetType()], endgameValues[piece.GetType()]}
+		if piece.GetColor() == board.White {
+			score = score.Add(value)
+		} else {
+			score = score.Sub(value)
+		}
+	})
+
+	return score
+}
+
+func PieceSquares(game *board.Game) Score {
+	score := Score{}
+	forEachPiece(game, func(coord board.Coordinate, piece board.Piece) {
+		row, col := coord.GetCoords()
+
+		// Tables are laid out with a8 first, so White's pieces are flipped
+		index := int(row)*8 + int(col)
+		if piece.GetColor() == board.White {
+			index = (7-int(row))*8 + int(col)
+		}
+
+		value := Score{middlegameTables[piece.GetType()][index], endgameTables[piece.GetType()][index]}
+		if piece.GetColor() == board.White {
+			score = score.Add(value)
+		} else {
+			score = score.Sub(value)
+		}
+	})
+
+	return score
+}
+
+func forEachPiece(game *board.Game, callback func(board.Coordinate, board.Piece)) {
+	for row := range game.Board {
+		for col, piece := range game.Board[row] {
+			if piece == 0 {
+				continue
+			}
+
+			callback(board.CreateCoordInt(row, col), piece)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msws/chess/board"
+)
+
+func getGame(t *testing.T, fen string) *board.Game {
+	game, err := board.FromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+// Flips the board vertically and swaps colors,
+// the resulting position should evaluate identically for the side to move
+func mirrorFEN(fen string) string {
+	fields := strings.Split(fen, " ")
+	ranks := strings.Split(fields[0], "/")
+
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+
+	swapCase := func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return r
+	}
+
+	fields[0] = strings.Map(swapCase, strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	fields[2] = strings.Map(swapCase, fields[2])
+
+	return strings.Join(fields, " ")
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected int
+	}{
+		"Starting Position": {
+			fen:      board.START_POSITION,
+			expected: 0,
+		},
+		"Starting Position - Black": {
+			fen:      "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1",
+			expected: 0,
+		},
+		"Bare Kings": {
+			fen:      "4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			expected: 0,
+		},
+		"Extra Queen": {
+			fen:      "4k3/8/8/8/3Q4/8/8/4K3 w - - 0 1",
+			expected: 988,
+		},
+		"Extra Queen - Black": {
+			fen:      "4k3/8/8/8/3Q4/8/8/4K3 b - - 0 1",
+			expected: -988,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result := Evaluate(getGame(t, test.fen))
+
+			if result != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestSymmetry(t *testing.T) {
+	fens := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	}
+
+	for _, fen := range fens {
+		t.Run(strings.ReplaceAll(fen, "/", "."), func(t *testing.T) {
+			original := Evaluate(getGame(t, fen))
+			mirrored := Evaluate(getGame(t, mirrorFEN(fen)))
+
+			if original != mirrored {
+				t.Errorf("expected mirrored position to evaluate to %d, got %d", original, mirrored)
+			}
+		})
+	}
+}
+
+func TestMaterial(t *testing.T) {
+	game := getGame(t, "4k3/8/8/8/8/8/8/RNBQK3 w - - 0 1")
+	score := Material(game)
+
+	expected := Score{
+		Middlegame: 477 + 337 + 365 + 1025,
+		Endgame:    512 + 281 + 297 + 936,
+	}
+
+	if score != expected {
+		t.Errorf("expected %v, got %v", expected, score)
+	}
+}
+
+func TestPhase(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected int
+	}{
+		"Starting Position": {
+			fen:      board.START_POSITION,
+			expected: MaxPhase,
+		},
+		"Pawn Endgame": {
+			fen:      "4k3/pppppppp/8/8/8/8/PPPPPPPP/4K3 w - - 0 1",
+			expected: 0,
+		},
+		"Rook Endgame": {
+			fen:      "4k2r/8/8/8/8/8/8/R3K3 w - - 0 1",
+			expected: 4,
+		},
+		"Promoted Queens": {
+			fen:      "QQQQk3/8/8/8/8/8/8/QQQQK3 w - - 0 1",
+			expected: MaxPhase,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			result := Phase(getGame(t, test.fen))
+
+			if result != test.expected {
+				t.Errorf("expected phase %d, got %d", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	bonus := func(game *board.Game) Score {
+		return Score{Middlegame: 10, Endgame: 20}
+	}
+
+	evaluator := Evaluator{Terms: []Term{Material, PieceSquares, bonus}}
+
+	t.Run("White", func(t *testing.T) {
+		result := evaluator.Evaluate(getGame(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1"))
+		if result != 20 {
+			t.Errorf("expected endgame bonus of %d, got %d", 20, result)
+		}
+	})
+
+	t.Run("Black", func(t *testing.T) {
+		result := evaluator.Evaluate(getGame(t, "4k3/8/8/8/8/8/8/4K3 b - - 0 1"))
+		if result != -20 {
+			t.Errorf("expected endgame bonus of %d, got %d", -20, result)
+		}
+	})
+}
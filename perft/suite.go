@@ -0,0 +1,131 @@
+package perft
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/epd"
+	"github.com/msws/chess/tt"
+)
+
+// A position from a perft suite along with its known node counts
+type Position struct {
+	FEN    string
+	Counts []Count
+}
+
+// The number of leaf nodes at Depth plies
+type Count struct {
+	Depth int
+	Nodes int
+}
+
+// The outcome of checking a Position's counts
+type Result struct {
+	Position Position
+
+	// Depth of the first count that did not match, 0 if all matched
+	FailedDepth int
+	Expected    int
+	Got         int
+
+	// Set if the position could not be set up
+	Err error
+}
+
+func (result Result) Passed() bool {
+	return result.Err == nil && result.FailedDepth == 0
+}
+
+func (result Result) String() string {
+	switch {
+	case result.Err != nil:
+		return fmt.Sprintf("ERROR %s: %v", result.Position.FEN, result.Err)
+	case result.FailedDepth != 0:
+		return fmt.Sprintf("FAIL %s: depth %d expected %d, got %d",
+			result.Position.FEN, result.FailedDepth, result.Expected, result.Got)
+	}
+
+	return fmt.Sprintf("PASS %s", result.Position.FEN)
+}
+
+// Reads a perft suite in EPD format, one position per line
+// followed by its counts (e.g. "<fen> ;D1 20 ;D2 400").
+// The move clocks may be omitted from the FEN.
+// Blank lines and lines starting with # are ignored.
+func ParseSuite(in io.Reader) ([]Position, error) {
+	records, err := epd.Parse(in)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Position, len(records))
+	for i, record := range records {
+		result[i], err = parsePosition(record)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %w", i+1, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Reads the perft suite at path, see ParseSuite
+func LoadSuite(path string) ([]Position, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseSuite(file)
+}
+
+func parsePosition(record epd.Record) (Position, error) {
+	counts, err := record.PerftCounts()
+	if err != nil {
+		return Position{}, err
+	}
+
+	if len(counts) != record.Operations.Len() {
+		return Position{}, fmt.Errorf("expected only perft counts, got %v", record.Operations.Opcodes())
+	}
+
+	position := Position{FEN: record.Position.ToFEN()}
+	for _, count := range counts {
+		position.Counts = append(position.Counts, Count{count.Depth, count.Nodes})
+	}
+
+	return position, nil
+}
+
+// Checks the position's counts in order of depth, stopping at the first
+// mismatch. Counts deeper than maxDepth are skipped, unless maxDepth is 0.
+// The table is passed to PerftParallel, so may be nil or shared between runs.
+func Run(position Position, maxDepth int, table *tt.Table[board.Move]) Result {
+	result := Result{Position: position}
+
+	game, err := board.FromFENLenient(position.FEN)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	for _, count := range position.Counts {
+		if maxDepth > 0 && count.Depth > maxDepth {
+			break
+		}
+
+		nodes := game.PerftParallel(count.Depth, 0, table)
+		if nodes != count.Nodes {
+			result.FailedDepth = count.Depth
+			result.Expected = count.Nodes
+			result.Got = nodes
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package perft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSuite(t *testing.T) {
+	input := `# Comment
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - ;D2 400 ;D1 20
+
+8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1 ;D1 14
+`
+
+	positions, err := ParseSuite(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %d", len(positions))
+	}
+
+	start := positions[0]
+	if start.FEN != "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1" {
+		t.Errorf("expected clocks to be added, got %v", start.FEN)
+	}
+
+	if len(start.Counts) != 2 || start.Counts[0] != (Count{1, 20}) || start.Counts[1] != (Count{2, 400}) {
+		t.Errorf("expected counts sorted by depth, got %v", start.Counts)
+	}
+
+	if positions[1].FEN != "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1" {
+		t.Errorf("expected full FEN to be kept, got %v", positions[1].FEN)
+	}
+}
+
+func TestParseSuiteErrors(t *testing.T) {
+	tests := map[string]string{
+		"Missing Nodes": "8/8/8/8/8/8/8/K6k w - - ;D1",
+		"Bad Depth":     "8/8/8/8/8/8/8/K6k w - - ;Dx 3",
+		"Zero Depth":    "8/8/8/8/8/8/8/K6k w - - ;D0 1",
+		"Bad Nodes":     "8/8/8/8/8/8/8/K6k w - - ;D1 three",
+		"Not A Count":   "8/8/8/8/8/8/8/K6k w - - ;bm e4",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseSuite(strings.NewReader(input)); err == nil {
+				t.Errorf("expected error for %q", input)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+	tests := map[string]struct {
+		position    Position
+		maxDepth    int
+		failedDepth int
+		err         bool
+	}{
+		"Pass": {
+			position: Position{FEN: start, Counts: []Count{{1, 20}, {2, 400}, {3, 8902}}},
+		},
+		"First Failure": {
+			position:    Position{FEN: start, Counts: []Count{{1, 20}, {2, 401}, {3, 8903}}},
+			failedDepth: 2,
+		},
+		"Limited Depth": {
+			position: Position{FEN: start, Counts: []Count{{1, 20}, {2, 400}, {3, 1}}},
+			maxDepth: 2,
+		},
+		"Invalid FEN": {
+			position: Position{FEN: "not a fen", Counts: []Count{{1, 20}}},
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := Run(test.position, test.maxDepth, nil)
+
+			if (result.Err != nil) != test.err {
+				t.Fatalf("unexpected error %v", result.Err)
+			}
+
+			if result.FailedDepth != test.failedDepth {
+				t.Errorf("expected failure at depth %d, got %v", test.failedDepth, result)
+			}
+
+			if result.Passed() != (test.failedDepth == 0 && !test.err) {
+				t.Errorf("unexpected Passed for %v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/msws/chess/board"
+)
+
+// A single game read from a PGN file
+type Game struct {
+	Tags map[string]string
+
+	// Mainline moves, in the order they were played
+	Moves []board.Move
+
+	// One of 1-0, 0-1, 1/2-1/2 or *
+	Result string
+
+	// Position after all moves have been played
+	Position *board.Game
+}
+
+// Describes where in the input a game failed to parse
+type Error struct {
+	// 1-based index of the game within the input
+	Game int
+	// 1-based ply the error occurred on, 0 if outside of the movetext
+	Ply   int
+	Token string
+	Err   error
+}
+
+func (err *Error) Error() string {
+	if err.Ply == 0 {
+		return fmt.Sprintf("game %d: %q: %v", err.Game, err.Token, err.Err)
+	}
+
+	return fmt.Sprintf("game %d, ply %d: %q: %v", err.Game, err.Ply, err.Token, err.Err)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+var moveNumber = regexp.MustCompile(`^[0-9]+\.+`)
+
+var results = map[string]bool{
+	"1-0":     true,
+	"0-1":     true,
+	"1/2-1/2": true,
+	"*":       true,
+}
+
+// Parses every game in the PGN input, replaying each mainline.
+// Returns the games parsed before the first error alongside an *Error.
+func Parse(r io.Reader) ([]Game, error) {
+	lexer := &lexer{reader: bufio.NewReader(r), lineStart: true}
+	games := []Game{}
+
+	for {
+		game, err := parseGame(lexer, len(games)+1)
+		if err == io.EOF {
+			return games, nil
+		}
+
+		if err != nil {
+			return games, err
+		}
+
+		games = append(games, *game)
+	}
+}
+
+func ParseString(str string) ([]Game, error) {
+	return Parse(strings.NewReader(str))
+}
+
+type gameParser struct {
+	index    int
+	game     *Game
+	position *board.Game
+	started  bool
+}
+
+func (parser *gameParser) fail(token string, err error) error {
+	ply := 0
+	if parser.position != nil {
+		ply = len(parser.position.Moves) + 1
+	}
+
+	return &Error{Game: parser.index, Ply: ply, Token: token, Err: err}
+}
+
+// Starts the movetext, setting up the initial position from the tags
+func (parser *gameParser) start() error {
+	parser.started = true
+	fen := board.START_POSITION
+	if setup, ok := parser.game.Tags["FEN"]; ok {
+		fen = setup
+	}
+
+	// Chess960 games give castling rights in X-FEN or Shredder-FEN
+	position, err := board.FromFENLenient(fen)
+	if err != nil {
+		return parser.fail(fen, err)
+	}
+
+	parser.position = position
+	return nil
+}
+
+func (parser *gameParser) finish() *Game {
+	parser.game.Position = parser.position
+	parser.game.Moves = append([]board.Move{}, parser.position.Moves...)
+
+	if parser.game.Result == "" {
+		parser.game.Result = "*"
+	}
+
+	return parser.game
+}
+
+func parseGame(lexer *lexer, index int) (*Game, error) {
+	parser := &gameParser{
+		index: index,
+		game:  &Game{Tags: map[string]string{}},
+	}
+
+	for {
+		tok, err := lexer.next()
+		if err == io.EOF {
+			if !parser.started && len(parser.game.Tags) == 0 {
+				return nil, io.EOF
+			}
+			if !parser.started {
+				if err := parser.start(); err != nil {
+					return nil, err
+				}
+			}
+			return parser.finish(), nil
+		}
+
+		if err != nil {
+			return nil, parser.fail(tok.text, err)
+		}
+
+		switch tok.kind {
+		case tagToken:
+			if parser.started {
+				// A new game without a result token for this one
+				lexer.unread(tok)
+				return parser.finish(), nil
+			}
+			parser.game.Tags[tok.name] = tok.text
+		case symbolToken:
+			if !parser.started {
+				if err := parser.start(); err != nil {
+					return nil, err
+				}
+			}
+
+			if results[tok.text] {
+				parser.game.Result = tok.text
+				return parser.finish(), nil
+			}
+
+			move, err := parser.position.ParseSAN(tok.text)
+			if err != nil {
+				return nil, parser.fail(tok.text, err)
+			}
+			parser.position.MakeMove(move)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	symbolToken tokenKind = iota
+	tagToken
+)
+
+type token struct {
+	kind tokenKind
+	// Tag name, only set for tags
+	name string
+	// Symbol, or the tag's value
+	text string
+}
+
+// Splits PGN into tag pairs and movetext symbols,
+// skipping comments, NAGs, move numbers and variations.
+type lexer struct {
+	reader    *bufio.Reader
+	lineStart bool
+	pending   *token
+}
+
+func (lexer *lexer) unread(tok token) {
+	lexer.pending = &tok
+}
+
+func (lexer *lexer) read() (rune, error) {
+	r, _, err := lexer.reader.ReadRune()
+	if err != nil {
+		return r, err
+	}
+
+	wasStart := lexer.lineStart
+	lexer.lineStart = r == '\n'
+
+	if wasStart && r == '%' {
+		// Escaped line
+		if err := lexer.skipUntil('\n'); err != nil {
+			return 0, err
+		}
+		lexer.lineStart = true
+		return lexer.read()
+	}
+
+	return r, nil
+}
+
+func (lexer *lexer) skipUntil(end rune) error {
+	for {
+		r, _, err := lexer.reader.ReadRune()
+		if err != nil {
+			return err
+		}
+		if r == end {
+			lexer.lineStart = r == '\n'
+			return nil
+		}
+	}
+}
+
+func (lexer *lexer) next() (token, error) {
+	if lexer.pending != nil {
+		tok := *lexer.pending
+		lexer.pending = nil
+		return tok, nil
+	}
+
+	variations := 0
+
+	for {
+		r, err := lexer.read()
+		if err != nil {
+			if err == io.EOF && variations > 0 {
+				return token{}, errors.New("unterminated variation")
+			}
+			return token{}, err
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+		case r == '{':
+			if err := lexer.skipUntil('}'); err != nil {
+				return token{text: "{"}, errors.New("unterminated comment")
+			}
+		case r == ';':
+			if err := lexer.skipUntil('\n'); err != nil && err != io.EOF {
+				return token{}, err
+			}
+		case r == '(':
+			variations++
+		case r == ')':
+			if variations == 0 {
+				return token{text: ")"}, errors.New("unbalanced variation")
+			}
+			variations--
+		case r == '[' && variations == 0:
+			return lexer.readTag()
+		default:
+			symbol, err := lexer.readSymbol(r)
+			if err != nil {
+				return token{}, err
+			}
+
+			if variations > 0 || skipSymbol(symbol) {
+				continue
+			}
+
+			// Move numbers may be attached to the move, as in 1.e4
+			symbol = moveNumber.ReplaceAllString(symbol, "")
+
+			return token{kind: symbolToken, text: symbol}, nil
+		}
+	}
+}
+
+func (lexer *lexer) readSymbol(first rune) (string, error) {
+	var sb strings.Builder
+	sb.WriteRune(first)
+
+	for {
+		r, _, err := lexer.reader.ReadRune()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if unicode.IsSpace(r) || strings.ContainsRune("{}()[];$", r) {
+			lexer.reader.UnreadRune()
+			return sb.String(), nil
+		}
+
+		sb.WriteRune(r)
+	}
+}
+
+// Whether the symbol carries no information for the mainline,
+// i.e. a NAG, annotation or move number
+func skipSymbol(symbol string) bool {
+	if symbol[0] == '$' {
+		return true
+	}
+
+	if strings.Trim(symbol, "!?") == "" {
+		return true
+	}
+
+	if results[symbol] {
+		return false
+	}
+
+	return strings.Trim(symbol, "0123456789.") == ""
+}
+
+// [Name "Value"]
+func (lexer *lexer) readTag() (token, error) {
+	var name, value strings.Builder
+	inValue, escaped, closed := false, false, false
+
+	for {
+		r, err := lexer.read()
+		if err != nil {
+			return token{text: name.String()}, errors.New("unterminated tag")
+		}
+
+		switch {
+		case inValue && escaped:
+			value.WriteRune(r)
+			escaped = false
+		case inValue && r == '\\':
+			escaped = true
+		case inValue && r == '"':
+			inValue = false
+			closed = true
+		case inValue:
+			value.WriteRune(r)
+		case r == '"':
+			if closed {
+				return token{text: name.String()}, errors.New("malformed tag")
+			}
+			inValue = true
+		case r == ']':
+			if !closed || name.Len() == 0 {
+				return token{text: name.String()}, errors.New("malformed tag")
+			}
+			return token{kind: tagToken, name: name.String(), text: value.String()}, nil
+		case unicode.IsSpace(r):
+		default:
+			if closed {
+				return token{text: name.String()}, errors.New("malformed tag")
+			}
+			name.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package pgn
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const operaGame = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[Round "?"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1.e4 e5 2.Nf3 d6 3.d4 Bg4 {This is a weak move already.} 4.dxe5 Bxf3 5.Qxf3 dxe5
+6.Bc4 Nf6 7.Qb3 Qe7 8.Nc3 c6 9.Bg5 {Black is in what's like a zugzwang position
+here.} b5 $6 10.Nxb5! cxb5 11.Bxb5+ Nbd7 12.O-O-O Rd8 13.Rxd7 Rxd7 14.Rd1 Qe6
+(14...Qb4 15.Bxf6 gxf6 16.Qxb4) 15.Bxd7+ Nxd7 16.Qb8+ Nxb8 17.Rd8# 1-0
+`
+
+// Only compares the position, side to move, castling and en passant
+func fenPrefix(fen string) string {
+	return strings.Join(strings.Split(fen, " ")[:4], " ")
+}
+
+func TestParse(t *testing.T) {
+	games, err := ParseString(operaGame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(games))
+	}
+
+	game := games[0]
+
+	if game.Tags["White"] != "Paul Morphy" || game.Tags["Black"] != "Duke Karl / Count Isouard" {
+		t.Errorf("unexpected tags %v", game.Tags)
+	}
+
+	if len(game.Tags) != 7 {
+		t.Errorf("expected 7 tags, got %d", len(game.Tags))
+	}
+
+	if game.Result != "1-0" {
+		t.Errorf("expected result 1-0, got %v", game.Result)
+	}
+
+	if len(game.Moves) != 33 {
+		t.Errorf("expected 33 plies, got %d", len(game.Moves))
+	}
+
+	expected := "1n1Rkb1r/p4ppp/4q3/4p1B1/4P3/8/PPP2PPP/2K5 b k -"
+	if fenPrefix(game.Position.ToFEN()) != expected {
+		t.Errorf("expected final position %v, got %v", expected, game.Position.ToFEN())
+	}
+}
+
+func TestParseMultiple(t *testing.T) {
+	input := `[Event "First"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+[Event "Second"]
+[Result "1/2-1/2"]
+
+1. e4 e5 2. Nf3 Nc6 1/2-1/2
+
+[Event "Third"]
+
+1. d4 d5 *
+`
+
+	games, err := ParseString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		event  string
+		result string
+		plies  int
+	}{
+		{event: "First", result: "0-1", plies: 4},
+		{event: "Second", result: "1/2-1/2", plies: 4},
+		{event: "Third", result: "*", plies: 2},
+	}
+
+	if len(games) != len(tests) {
+		t.Fatalf("expected %d games, got %d", len(tests), len(games))
+	}
+
+	for i, test := range tests {
+		t.Run(test.event, func(t *testing.T) {
+			game := games[i]
+			if game.Tags["Event"] != test.event {
+				t.Errorf("expected event %v, got %v", test.event, game.Tags["Event"])
+			}
+			if game.Result != test.result {
+				t.Errorf("expected result %v, got %v", test.result, game.Result)
+			}
+			if len(game.Moves) != test.plies {
+				t.Errorf("expected %d plies, got %d", test.plies, len(game.Moves))
+			}
+		})
+	}
+}
+
+func TestParseMovetext(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"No Tags": {
+			input:    "1. e4 e5 *",
+			expected: "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6",
+		},
+		"Missing Result": {
+			input:    "1. e4 e5",
+			expected: "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6",
+		},
+		"Nested Variations": {
+			input:    "1. e4 (1. d4 d5 (1... Nf6 2. c4) 2. c4) 1... c5 *",
+			expected: "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6",
+		},
+		"Comments and NAGs": {
+			input:    "1. e4 $1 {best by test} e5 ; rest of line ignored d4\n2. Nf3!? *",
+			expected: "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq -",
+		},
+		"Escaped Line": {
+			input:    "% 1. d4\n1. e4 e5 *",
+			expected: "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6",
+		},
+		"Zero Castling": {
+			input:    "[SetUp \"1\"]\n[FEN \"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1\"]\n\n1. 0-0 0-0-0 *",
+			expected: "2kr3r/8/8/8/8/8/8/R4RK1 w - -",
+		},
+		"Chess960 Castling": {
+			input:    "[Variant \"Chess960\"]\n[FEN \"rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1\"]\n\n1. O-O O-O-O *",
+			expected: "2kr3r/pppppppp/8/8/8/8/PPPPPPPP/R4RK1 w - -",
+		},
+		"Under-Promotion": {
+			input:    "[FEN \"8/1P6/8/8/8/8/6p1/k1K4R b - - 0 1\"]\n\n1... gxh1=N 2. b8=R+ *",
+			expected: "1R6/8/8/8/8/8/8/k1K4n b - -",
+		},
+		"Disambiguation": {
+			input:    "[FEN \"k7/8/8/8/8/5N2/8/KN3N2 w - - 0 1\"]\n\n1. Nbd2 Kb8 2. N3h2 *",
+			expected: "1k6/8/8/8/8/8/3N3N/K4N2 b - -",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			games, err := ParseString(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(games) != 1 {
+				t.Fatalf("expected 1 game, got %d", len(games))
+			}
+
+			if fenPrefix(games[0].Position.ToFEN()) != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, games[0].Position.ToFEN())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		game  int
+		ply   int
+		token string
+	}{
+		"Illegal Move": {
+			input: "1. e4 e5 2. Ke3 *",
+			game:  1,
+			ply:   3,
+			token: "Ke3",
+		},
+		"Second Game": {
+			input: "1. e4 e5 *\n\n1. d4 d5 2. c4 dxc4 3. Bxb5 *",
+			game:  2,
+			ply:   5,
+			token: "Bxb5",
+		},
+		"Ambiguous": {
+			input: "[FEN \"k7/8/8/8/8/8/8/KN3N2 w - - 0 1\"]\n\n1. Nd2 *",
+			game:  1,
+			ply:   1,
+			token: "Nd2",
+		},
+		"Gibberish": {
+			input: "1. e4 hello *",
+			game:  1,
+			ply:   2,
+			token: "hello",
+		},
+		"Bare Promotion": {
+			input: "1. =Q *",
+			game:  1,
+			ply:   1,
+			token: "=Q",
+		},
+		"Bad FEN": {
+			input: "[FEN \"8/8/8\"]\n\n1. e4 *",
+			game:  1,
+			ply:   0,
+			token: "8/8/8",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseString(test.input)
+
+			var pgnErr *Error
+			if !errors.As(err, &pgnErr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+
+			if pgnErr.Game != test.game || pgnErr.Ply != test.ply || pgnErr.Token != test.token {
+				t.Errorf("expected game %d, ply %d, token %q, got %v", test.game, test.ply, test.token, pgnErr)
+			}
+		})
+	}
+
+	t.Run("Unterminated Comment", func(t *testing.T) {
+		if _, err := ParseString("1. e4 {oops"); err == nil {
+			t.Error("expected error for unterminated comment")
+		}
+	})
+
+	t.Run("Keeps Earlier Games", func(t *testing.T) {
+		games, err := ParseString("1. e4 e5 *\n\n1. e5 *")
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if len(games) != 1 {
+			t.Errorf("expected the first game to be returned, got %d games", len(games))
+		}
+	})
+}
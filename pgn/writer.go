@@ -0,0 +1,147 @@
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/msws/chess/board"
+)
+
+// Maximum length of a movetext line
+const lineWidth = 80
+
+// The Seven Tag Roster, always written first and in this order
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+var rosterDefaults = map[string]string{
+	"Date": "????.??.??",
+}
+
+// Writes the moves played in game as PGN, leaving game unchanged.
+// Missing Seven Tag Roster entries are filled with "?",
+// and the Result tag is derived from the final position if not given.
+func Write(w io.Writer, game *board.Game, tags map[string]string) error {
+	game = game.Clone()
+	moves := append([]board.Move{}, game.Moves...)
+
+	// Rewind the clone to the starting position,
+	// replaying the moves below brings it back
+	for range moves {
+		game.UndoMove()
+	}
+
+	allTags := map[string]string{}
+	for name, value := range tags {
+		allTags[name] = value
+	}
+
+	if start := game.ToFEN(); start != board.START_POSITION {
+		allTags["SetUp"] = "1"
+		allTags["FEN"] = start
+	}
+
+	if _, ok := allTags["Variant"]; !ok && game.Chess960 {
+		allTags["Variant"] = "Chess960"
+	}
+
+	movetext := []string{}
+	number := game.FullMoves
+	for i, move := range moves {
+		if game.Active == board.White {
+			movetext = append(movetext, fmt.Sprintf("%d.", number))
+		} else if i == 0 {
+			movetext = append(movetext, fmt.Sprintf("%d...", number))
+		}
+
+		if game.Active == board.Black {
+			number++
+		}
+
+		movetext = append(movetext, move.GetAlgebra(game))
+		game.MakeMove(move)
+	}
+
+	if _, ok := allTags["Result"]; !ok {
+		allTags["Result"] = result(game)
+	}
+	movetext = append(movetext, allTags["Result"])
+
+	out := bufio.NewWriter(w)
+	writeTags(out, allTags)
+	out.WriteRune('\n')
+	writeWrapped(out, movetext)
+	out.WriteString("\n\n")
+
+	return out.Flush()
+}
+
+func Format(game *board.Game, tags map[string]string) string {
+	var sb strings.Builder
+	Write(&sb, game, tags)
+	return sb.String()
+}
+
+func result(game *board.Game) string {
+	switch game.Status() {
+	case board.Ongoing, board.FiftyMoveDraw, board.ThreefoldRepetition:
+		// Claimable draws only end the game once claimed
+		return "*"
+	case board.Checkmate:
+		if winner, _ := game.Winner(); winner == board.Black {
+			return "0-1"
+		}
+		return "1-0"
+	}
+
+	return "1/2-1/2"
+}
+
+func writeTags(out *bufio.Writer, tags map[string]string) {
+	writeTag := func(name string) {
+		value, ok := tags[name]
+		if !ok {
+			value = "?"
+			if def, ok := rosterDefaults[name]; ok {
+				value = def
+			}
+		}
+
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		fmt.Fprintf(out, "[%s \"%s\"]\n", name, value)
+	}
+
+	names := []string{}
+	for name := range tags {
+		if !slices.Contains(sevenTagRoster, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range append(sevenTagRoster, names...) {
+		writeTag(name)
+	}
+}
+
+func writeWrapped(out *bufio.Writer, tokens []string) {
+	length := 0
+	for _, token := range tokens {
+		if length > 0 && length+1+len(token) > lineWidth {
+			out.WriteRune('\n')
+			length = 0
+		}
+
+		if length > 0 {
+			out.WriteRune(' ')
+			length++
+		}
+
+		out.WriteString(token)
+		length += len(token)
+	}
+}
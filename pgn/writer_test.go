@@ -0,0 +1,215 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msws/chess/board"
+)
+
+func TestWrite(t *testing.T) {
+	games, err := ParseString(operaGame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[Round "?"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 4. dxe5 Bxf3 5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8.
+Nc3 c6 9. Bg5 b5 10. Nxb5 cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8 13. Rxd7 Rxd7 14.
+Rd1 Qe6 15. Bxd7+ Nxd7 16. Qb8+ Nxb8 17. Rd8# 1-0
+
+`
+
+	fen := games[0].Position.ToFEN()
+	result := Format(games[0].Position, games[0].Tags)
+	if result != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, result)
+	}
+
+	if len(games[0].Position.Moves) != 33 {
+		t.Errorf("writing should leave the game's moves intact, got %d", len(games[0].Position.Moves))
+	}
+
+	if games[0].Position.ToFEN() != fen {
+		t.Errorf("writing should leave the game's position intact, expected %v, got %v", fen, games[0].Position.ToFEN())
+	}
+}
+
+func TestWriteTags(t *testing.T) {
+	game, err := board.FromFEN(board.START_POSITION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Format(game, map[string]string{
+		"White":       `Magnus "The Hammer"`,
+		"Annotator":   `C:\`,
+		"ECO":         "A00",
+		"WhiteElo":    "2800",
+		"TimeControl": "40/7200",
+	})
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Magnus \"The Hammer\""]
+[Black "?"]
+[Result "*"]
+[Annotator "C:\\"]
+[ECO "A00"]
+[TimeControl "40/7200"]
+[WhiteElo "2800"]
+
+*
+
+`
+
+	if result != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, result)
+	}
+}
+
+func TestWriteSetUp(t *testing.T) {
+	fen := "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 12"
+	game, err := board.FromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game.MakeMove(game.CreateMoveStr("e8", "a8"))
+	game.MakeMove(game.CreateMoveStr("e1", "h1"))
+
+	result := Format(game, nil)
+
+	if !strings.Contains(result, `[SetUp "1"]`) || !strings.Contains(result, `[FEN "`+fen+`"]`) {
+		t.Errorf("expected SetUp and FEN tags, got\n%v", result)
+	}
+
+	if !strings.HasSuffix(result, "\n12... O-O-O 13. O-O *\n\n") {
+		t.Errorf("expected movetext to start with black, got\n%v", result)
+	}
+
+	if strings.Contains(result, "Variant") {
+		t.Errorf("expected no Variant tag for standard chess, got\n%v", result)
+	}
+
+	t.Run("Chess960", func(t *testing.T) {
+		fen := "rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1"
+		game, err := board.FromFENLenient(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		move, err := game.ParseSAN("O-O")
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.MakeMove(move)
+
+		result := Format(game, nil)
+		if !strings.Contains(result, `[Variant "Chess960"]`) || !strings.Contains(result, `[FEN "`+fen+`"]`) {
+			t.Errorf("expected Variant and Shredder-FEN tags, got\n%v", result)
+		}
+
+		if !strings.HasSuffix(result, "\n1. O-O *\n\n") {
+			t.Errorf("expected O-O, got\n%v", result)
+		}
+	})
+}
+
+func TestWriteResult(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"White Mates": {
+			input:    "1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7#",
+			expected: "1-0",
+		},
+		"Black Mates": {
+			input:    "1. f3 e5 2. g4 Qh4#",
+			expected: "0-1",
+		},
+		"Stalemate": {
+			input:    "[FEN \"7k/8/6QK/8/8/8/8/8 w - - 0 1\"]\n\n1. Qf7",
+			expected: "1/2-1/2",
+		},
+		"Insufficient Material": {
+			input:    "[FEN \"k7/8/8/8/8/8/1q6/K7 w - - 0 1\"]\n\n1. Kxb2",
+			expected: "1/2-1/2",
+		},
+		"Ongoing": {
+			input:    "1. e4",
+			expected: "*",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			games, err := ParseString(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := Format(games[0].Position, nil)
+			if !strings.Contains(result, `[Result "`+test.expected+`"]`) || !strings.HasSuffix(result, " "+test.expected+"\n\n") {
+				t.Errorf("expected result %v, got\n%v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteWrapping(t *testing.T) {
+	game, err := board.FromFEN(board.START_POSITION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shuffle the knights back and forth to get a long movetext
+	for i := 0; i < 40; i++ {
+		for _, move := range [][2]string{{"g1", "f3"}, {"g8", "f6"}, {"f3", "g1"}, {"f6", "g8"}} {
+			game.MakeMove(game.CreateMoveStr(move[0], move[1]))
+		}
+	}
+
+	for _, line := range strings.Split(Format(game, nil), "\n") {
+		if len(line) > lineWidth {
+			t.Errorf("line exceeds %d columns: %q", lineWidth, line)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		operaGame,
+		"1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0",
+		"[FEN \"8/1P6/8/8/8/8/6p1/k1K4R b - - 0 1\"]\n\n1... gxh1=N 2. b8=R+ *",
+		"[FEN \"k7/8/8/8/8/5N2/8/KN3N2 w - - 0 1\"]\n\n1. Nbd2 Kb8 2. N3h2 *",
+		"1. e4 d5 2. e5 f5 3. exf6 e5 4. fxg7 Ke7 5. gxh8=Q *",
+	}
+
+	for _, input := range inputs {
+		games, err := ParseString(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		written := Format(games[0].Position, games[0].Tags)
+		reread, err := ParseString(written)
+		if err != nil {
+			t.Fatalf("failed to parse written PGN: %v\n%v", err, written)
+		}
+
+		if reread[0].Position.ToFEN() != games[0].Position.ToFEN() {
+			t.Errorf("expected %v, got %v", games[0].Position.ToFEN(), reread[0].Position.ToFEN())
+		}
+	}
+}
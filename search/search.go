@@ -0,0 +1,438 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/eval"
+	"github.com/msws/chess/tt"
+)
+
+const (
+	// Maximum number of plies the search will look ahead
+	MaxDepth = 64
+
+	Infinity  = 1_000_000
+	MateScore = 100_000
+
+	// Size of the transposition table used when Limits.Table is nil, in megabytes
+	TableSize = 16
+
+	// Checking the clock every node is needlessly expensive,
+	// so it is only checked every checkInterval nodes
+	checkInterval = 256
+)
+
+// Limits bound how long a search may run.
+// Zero values are treated as unlimited,
+// though a search without any limits stops at MaxDepth.
+type Limits struct {
+	Depth    int
+	Nodes    int
+	MoveTime time.Duration
+
+	// Restricts the root to these moves (UCI searchmoves),
+	// ignored if none of them are legal
+	Moves []board.Move
+
+	// Stops the search as soon as possible once set
+	Stop *atomic.Bool
+
+	// Transposition table to search with, which may be kept between
+	// searches of the same game. A new one of TableSize is used if nil.
+	Table *tt.Table[board.Move]
+
+	// Called after every completed iteration
+	Info func(Result)
+}
+
+type Result struct {
+	Move board.Move
+
+	// Centipawns from the perspective of the side to move,
+	// see IsMate and MateIn for mate scores
+	Score int
+
+	Depth int
+	Nodes int
+	Time  time.Duration
+
+	// Principal variation, starting with Move
+	PV []board.Move
+}
+
+func (result Result) IsMate() bool {
+	return result.Score > MateScore-MaxDepth || result.Score < -MateScore+MaxDepth
+}
+
+// Number of moves until mate, negative if the side to move is getting mated.
+// Returns 0 if the score is not a mate score.
+func (result Result) MateIn() int {
+	if !result.IsMate() {
+		return 0
+	}
+
+	if result.Score > 0 {
+		return (MateScore - result.Score + 1) / 2
+	}
+
+	return -(MateScore + result.Score) / 2
+}
+
+type searcher struct {
+	game   *board.Game
+	limits Limits
+	table  *tt.Table[board.Move]
+
+	start    time.Time
+	deadline time.Time
+	nodes    int
+	stopped  bool
+
+	// Number of times a repetition or the fifty-move rule was scored
+	// as a draw. Those scores depend on how the position was reached,
+	// so nodes whose subtree contains one are not stored in the table.
+	historyDraws int
+
+	pv       [MaxDepth + 1][MaxDepth + 1]board.Move
+	pvLength [MaxDepth + 1]int
+
+	// Principal variation of the last completed iteration
+	previousPV []board.Move
+}
+
+// Searches game with iterative deepening until a limit is reached,
+// returning the result of the deepest completed iteration.
+// The game is left unchanged once the search returns.
+func Search(game *board.Game, limits Limits) Result {
+	s := &searcher{
+		game:   game,
+		limits: limits,
+		table:  limits.Table,
+		start:  time.Now(),
+	}
+
+	if s.table == nil {
+		s.table = tt.New[board.Move](TableSize)
+	}
+
+	if limits.MoveTime > 0 {
+		s.deadline = s.start.Add(limits.MoveTime)
+	}
+
+	maxDepth := MaxDepth
+	if limits.Depth > 0 && limits.Depth < MaxDepth {
+		maxDepth = limits.Depth
+	}
+
+	result := Result{}
+	moves := s.rootMoves(game.GetMoves())
+
+	if len(moves) == 0 {
+		if game.InCheck() {
+			result.Score = -MateScore
+		}
+		return result
+	}
+
+	// Always have a move to return, even if the first iteration is cut short
+	result.Move = moves[0]
+	result.PV = []board.Move{moves[0]}
+
+	for depth := 1; depth <= maxDepth; depth++ {
+		score := s.negamax(depth, 0, -Infinity, Infinity)
+
+		if s.stopped {
+			break
+		}
+
+		result.Score = score
+		result.Depth = depth
+		result.PV = append([]board.Move{}, s.pv[0][:s.pvLength[0]]...)
+		result.Move = result.PV[0]
+		result.Nodes = s.nodes
+		result.Time = time.Since(s.start)
+		s.previousPV = result.PV
+
+		if limits.Info != nil {
+			limits.Info(result)
+		}
+
+		if len(moves) == 1 || result.IsMate() {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	result.Time = time.Since(s.start)
+	return result
+}
+
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	s.pvLength[ply] = ply
+
+	if depth <= 0 || ply >= MaxDepth {
+		return s.quiesce(ply, alpha, beta)
+	}
+
+	s.nodes++
+	if s.shouldStop() {
+		return 0
+	}
+
+	// Repeating a position (or running out the fifty-move clock)
+	// can be claimed as a draw, so it is scored as one
+	if ply > 0 && (s.game.RepetitionCount() > 1 || s.game.HalfMoves >= 100) {
+		s.historyDraws++
+		return 0
+	}
+
+	historyDraws := s.historyDraws
+
+	var tableMove *board.Move
+	if entry, ok := s.table.Probe(s.game.Hash()); ok {
+		tableMove = &entry.Move
+		score := fromTable(entry.Score, ply)
+
+		if ply > 0 && entry.Depth >= depth {
+			switch {
+			case entry.Bound == tt.Exact,
+				entry.Bound == tt.Lower && score >= beta,
+				entry.Bound == tt.Upper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	moves := s.game.GetMoves()
+	if len(moves) == 0 {
+		if s.game.InCheck() {
+			return -MateScore + ply
+		}
+		return 0
+	}
+
+	if ply == 0 {
+		moves = s.rootMoves(moves)
+	}
+
+	s.orderMoves(moves, ply, tableMove)
+
+	originalAlpha := alpha
+	best := -Infinity
+	var bestMove board.Move
+
+	for _, move := range moves {
+		s.game.MakeMove(move)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		s.game.UndoMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score <= best {
+			continue
+		}
+
+		best = score
+		bestMove = move
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, move)
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if s.historyDraws != historyDraws {
+		return best
+	}
+
+	bound := tt.Exact
+	if best <= originalAlpha {
+		bound = tt.Upper
+	} else if best >= beta {
+		bound = tt.Lower
+	}
+
+	s.table.Store(tt.Entry[board.Move]{
+		Hash:  s.game.Hash(),
+		Depth: depth,
+		Bound: bound,
+		Score: toTable(best, ply),
+		Move:  bestMove,
+	})
+
+	return best
+}
+
+// Only searches captures and promotions so that
+// the static evaluation is never taken mid-exchange.
+func (s *searcher) quiesce(ply, alpha, beta int) int {
+	s.pvLength[ply] = ply
+	s.nodes++
+
+	if s.shouldStop() {
+		return 0
+	}
+
+	standPat := eval.Evaluate(s.game)
+	if standPat >= beta || ply >= MaxDepth {
+		return standPat
+	}
+
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	moves := filter(s.game.GetMoves(), isTactical)
+	s.orderMoves(moves, ply, nil)
+
+	for _, move := range moves {
+		s.game.MakeMove(move)
+		score := -s.quiesce(ply+1, -beta, -alpha)
+		s.game.UndoMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, move)
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return alpha
+}
+
+func (s *searcher) rootMoves(moves []board.Move) []board.Move {
+	allowed := filter(moves, func(move board.Move) bool {
+		return slices.Contains(s.limits.Moves, move)
+	})
+
+	if len(allowed) == 0 {
+		return moves
+	}
+
+	return allowed
+}
+
+func (s *searcher) updatePV(ply int, move board.Move) {
+	s.pv[ply][ply] = move
+	copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pvLength[ply+1]])
+	s.pvLength[ply] = s.pvLength[ply+1]
+	if s.pvLength[ply] <= ply {
+		s.pvLength[ply] = ply + 1
+	}
+}
+
+func (s *searcher) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+
+	if s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes {
+		s.stopped = true
+	}
+
+	if s.limits.Stop != nil && s.limits.Stop.Load() {
+		s.stopped = true
+	}
+
+	if s.nodes%checkInterval == 0 && !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+
+	return s.stopped
+}
+
+// Orders moves so that the previous iteration's principal variation
+// is searched first, then the transposition table's best move,
+// followed by captures (most valuable victim first).
+func (s *searcher) orderMoves(moves []board.Move, ply int, tableMove *board.Move) {
+	var pvMove *board.Move
+	if ply < len(s.previousPV) {
+		pvMove = &s.previousPV[ply]
+	}
+
+	scores := make([]int, len(moves))
+	for i, move := range moves {
+		if pvMove != nil && move == *pvMove {
+			scores[i] = Infinity
+		} else if tableMove != nil && move == *tableMove {
+			scores[i] = Infinity - 1
+		} else if isTactical(move) {
+			captured := move.Capture
+			if move.IsEnPassant() {
+				captured = board.Pawn
+			}
+			scores[i] = 10*eval.PieceValue(captured) - eval.PieceValue(move.Piece) + eval.PieceValue(move.Promotion())
+		}
+	}
+
+	sort.Stable(byScore{moves, scores})
+}
+
+type byScore struct {
+	moves  []board.Move
+	scores []int
+}
+
+func (s byScore) Len() int           { return len(s.moves) }
+func (s byScore) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+func (s byScore) Swap(i, j int) {
+	s.moves[i], s.moves[j] = s.moves[j], s.moves[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
+// Mate scores are relative to the root, but are stored
+// relative to the position so they remain valid at any ply
+func toTable(score, ply int) int {
+	switch {
+	case score > MateScore-MaxDepth:
+		return score + ply
+	case score < -MateScore+MaxDepth:
+		return score - ply
+	}
+
+	return score
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score > MateScore-MaxDepth:
+		return score - ply
+	case score < -MateScore+MaxDepth:
+		return score + ply
+	}
+
+	return score
+}
+
+func isTactical(move board.Move) bool {
+	return move.IsCapture() || move.IsPromotion()
+}
+
+func filter[T any](arr []T, predicate func(T) bool) []T {
+	ret := []T{}
+	for _, t := range arr {
+		if !predicate(t) {
+			continue
+		}
+
+		ret = append(ret, t)
+	}
+
+	return ret
+}
@@ -0,0 +1,221 @@
+package search
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/tt"
+)
+
+func getGame(t *testing.T, fen string) *board.Game {
+	game, err := board.FromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+func TestSearch(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		depth    int
+		from, to string
+		mateIn   int
+	}{
+		"Back Rank Mate": {
+			fen:    "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			depth:  2,
+			from:   "a1",
+			to:     "a8",
+			mateIn: 1,
+		},
+		"Back Rank Mate - Black": {
+			fen:    "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 1",
+			depth:  2,
+			from:   "a8",
+			to:     "a1",
+			mateIn: 1,
+		},
+		"Hanging Queen": {
+			fen:   "4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1",
+			depth: 2,
+			from:  "d2",
+			to:    "d5",
+		},
+		// Either rook can start the ladder, so only the mate is checked
+		"Ladder Mate in 2": {
+			fen:    "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1",
+			depth:  4,
+			mateIn: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			game := getGame(t, test.fen)
+
+			result := Search(game, Limits{Depth: test.depth})
+
+			if test.from != "" && (result.Move.From.GetAlgebra() != test.from || result.Move.To.GetAlgebra() != test.to) {
+				t.Errorf("expected %v%v, got %v (pv %v)", test.from, test.to, result.Move, result.PV)
+			}
+
+			if result.MateIn() != test.mateIn {
+				t.Errorf("expected mate in %d, got %d (score %d)", test.mateIn, result.MateIn(), result.Score)
+			}
+
+			if game.ToFEN() != test.fen {
+				t.Errorf("search did not restore the game, expected %v, got %v", test.fen, game.ToFEN())
+			}
+		})
+	}
+}
+
+func TestSearchResult(t *testing.T) {
+	t.Run("Depth", func(t *testing.T) {
+		result := Search(getGame(t, board.START_POSITION), Limits{Depth: 2})
+
+		if result.Depth != 2 {
+			t.Errorf("expected depth %d, got %d", 2, result.Depth)
+		}
+
+		if len(result.PV) == 0 || result.PV[0] != result.Move {
+			t.Errorf("expected principal variation to start with %v, got %v", result.Move, result.PV)
+		}
+
+		if result.Nodes == 0 {
+			t.Error("expected nodes to be counted")
+		}
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		depths := []int{}
+		Search(getGame(t, board.START_POSITION), Limits{
+			Depth: 3,
+			Info: func(result Result) {
+				depths = append(depths, result.Depth)
+			},
+		})
+
+		if len(depths) != 3 || depths[0] != 1 || depths[2] != 3 {
+			t.Errorf("expected an update per iteration, got %v", depths)
+		}
+	})
+
+	t.Run("Checkmated", func(t *testing.T) {
+		result := Search(getGame(t, "1R3k2/2R5/8/8/8/1K6/8/8 b - - 0 1"), Limits{Depth: 3})
+
+		if len(result.PV) != 0 {
+			t.Errorf("expected no moves, got %v", result.PV)
+		}
+
+		if !result.IsMate() || result.Score > 0 {
+			t.Errorf("expected to be mated, got score %d", result.Score)
+		}
+	})
+
+	t.Run("Stalemated", func(t *testing.T) {
+		result := Search(getGame(t, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"), Limits{Depth: 3})
+
+		if len(result.PV) != 0 || result.Score != 0 {
+			t.Errorf("expected a drawn result without moves, got %v (%d)", result.PV, result.Score)
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("Nodes", func(t *testing.T) {
+		result := Search(getGame(t, board.START_POSITION), Limits{Nodes: 500})
+
+		if result.Nodes > 500 {
+			t.Errorf("expected at most %d nodes, got %d", 500, result.Nodes)
+		}
+
+		if len(result.PV) == 0 {
+			t.Error("expected a move even when stopped early")
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		stop := atomic.Bool{}
+		stop.Store(true)
+
+		result := Search(getGame(t, board.START_POSITION), Limits{Stop: &stop})
+
+		if result.Depth > 1 {
+			t.Errorf("expected search to stop immediately, reached depth %d", result.Depth)
+		}
+
+		if len(result.PV) == 0 {
+			t.Error("expected a move even when stopped early")
+		}
+	})
+}
+
+func TestSearchMoves(t *testing.T) {
+	game := getGame(t, "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	quiet, err := game.ParseUCIMove("g1f1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Search(game, Limits{Depth: 2, Moves: []board.Move{quiet}})
+	if result.Move != quiet {
+		t.Errorf("expected the search to be restricted to %v, got %v", quiet, result.Move)
+	}
+}
+
+func TestTable(t *testing.T) {
+	t.Run("Kept Between Searches", func(t *testing.T) {
+		game := getGame(t, board.START_POSITION)
+		table := tt.New[board.Move](1)
+		Search(game, Limits{Depth: 2, Table: table})
+
+		if entry, ok := table.Probe(game.Hash()); !ok || entry.Depth != 2 {
+			t.Errorf("expected the root to be stored at depth 2, got %+v (%v)", entry, ok)
+		}
+	})
+
+	t.Run("Repetition Not Stored", func(t *testing.T) {
+		game := getGame(t, board.START_POSITION)
+		for _, str := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+			move, err := game.ParseUCIMove(str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			game.MakeMove(move)
+		}
+
+		// Nf3 now repeats a position, so once the second iteration sees it
+		// the root's score depends on the game's history
+		table := tt.New[board.Move](1)
+		Search(game, Limits{Depth: 2, Table: table})
+
+		if entry, ok := table.Probe(game.Hash()); ok && entry.Depth == 2 {
+			t.Errorf("expected the depth 2 root not to be stored, got %+v", entry)
+		}
+	})
+}
+
+func TestMateIn(t *testing.T) {
+	tests := []struct {
+		score  int
+		mateIn int
+	}{
+		{score: MateScore - 1, mateIn: 1},
+		{score: MateScore - 3, mateIn: 2},
+		{score: -MateScore + 2, mateIn: -1},
+		{score: -MateScore + 4, mateIn: -2},
+		{score: 150, mateIn: 0},
+	}
+
+	for _, test := range tests {
+		result := Result{Score: test.score}
+		if result.MateIn() != test.mateIn {
+			t.Errorf("expected score %d to be mate in %d, got %d", test.score, test.mateIn, result.MateIn())
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -23,6 +24,10 @@ type TestCases struct {
 type TestStart struct {
 	Fen         string `json:"fen"`
 	Description string `json:"description"`
+
+	// Expected Game.Status and winning color ("w" or "b"), if specified
+	Status string `json:"status"`
+	Winner string `json:"winner"`
 }
 
 type TestExpectation struct {
@@ -38,6 +43,10 @@ func TestJSON(t *testing.T) {
 	}
 
 	for _, entry := range data {
+		if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
 		t.Run(entry.Name(), func(t *testing.T) {
 			jsonData, err := os.Open(entry.Name())
 			if err != nil {
@@ -77,6 +86,16 @@ func testCase(t *testing.T, start TestStart, expected []TestExpectation) {
 	t.Run("MoveCount", func(t *testing.T) {
 		testMoveCount(t, start, expected)
 	})
+
+	t.Run("Positions", func(t *testing.T) {
+		testPositions(t, start, expected)
+	})
+
+	if start.Status != "" {
+		t.Run("Status", func(t *testing.T) {
+			testStatus(t, start)
+		})
+	}
 }
 
 func testMoveCount(t *testing.T, start TestStart, expected []TestExpectation) {
@@ -93,3 +112,67 @@ func testMoveCount(t *testing.T, start TestStart, expected []TestExpectation) {
 		t.Errorf("expected %d moves, got %d", len(expected), len(moves))
 	}
 }
+
+// Plays each expected move from the start position, comparing the
+// resulting FEN in full (including clocks), then undoes it.
+// Every FEN involved must also pass strict validation.
+func testPositions(t *testing.T, start TestStart, expected []TestExpectation) {
+	if err := board.ValidateFEN(start.Fen); err != nil {
+		t.Error(err)
+	}
+
+	game, err := board.FromFEN(start.Fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expectation := range expected {
+		move, err := game.ParseSAN(expectation.Move)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if err := board.ValidateFEN(expectation.Fen); err != nil {
+			t.Errorf("after %v: %v", expectation.Move, err)
+		}
+
+		game.MakeMove(move)
+		if game.ToFEN() != expectation.Fen {
+			t.Errorf("after %v expected %v, got %v", expectation.Move, expectation.Fen, game.ToFEN())
+		}
+
+		game.UndoMove()
+		if game.ToFEN() != start.Fen {
+			t.Errorf("undoing %v expected %v, got %v", expectation.Move, start.Fen, game.ToFEN())
+		}
+	}
+}
+
+func testStatus(t *testing.T, start TestStart) {
+	game, err := board.FromFEN(start.Fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := game.Status(); status.String() != start.Status {
+		t.Errorf("expected status %v, got %v", start.Status, status)
+	}
+
+	winner, ok := game.Winner()
+	if start.Winner == "" {
+		if ok {
+			t.Errorf("expected no winner, got %v", winner)
+		}
+		return
+	}
+
+	expected := board.White
+	if start.Winner == "b" {
+		expected = board.Black
+	}
+
+	if !ok || winner != expected {
+		t.Errorf("expected %v to win, got %v (%v)", start.Winner, winner, ok)
+	}
+}
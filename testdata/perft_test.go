@@ -0,0 +1,62 @@
+package testdata
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/perft"
+	"github.com/msws/chess/tt"
+)
+
+var perftNodes = flag.Int("perft-nodes", 5_000_000, "skip perft suite counts above this many nodes, 0 for no limit")
+
+// Depth perft suite counts are limited to with -short
+const shortPerftDepth = 3
+
+func TestPerftSuite(t *testing.T) {
+	suites, err := filepath.Glob("*.epd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, suite := range suites {
+		t.Run(suite, func(t *testing.T) {
+			testPerftSuite(t, suite)
+		})
+	}
+}
+
+func testPerftSuite(t *testing.T, path string) {
+	positions, err := perft.LoadSuite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shared by the suite's positions, which run one at a time
+	table := tt.New[board.Move](64)
+
+	for _, position := range positions {
+		t.Run(strings.ReplaceAll(position.FEN, "/", "."), func(t *testing.T) {
+			counts := []perft.Count{}
+			for _, count := range position.Counts {
+				if *perftNodes > 0 && count.Nodes > *perftNodes {
+					break
+				}
+				counts = append(counts, count)
+			}
+			position.Counts = counts
+
+			maxDepth := 0
+			if testing.Short() {
+				maxDepth = shortPerftDepth
+			}
+
+			if result := perft.Run(position, maxDepth, table); !result.Passed() {
+				t.Error(result)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package tt
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Number of locks guarding the table, each covering an interleaved
+// share of the buckets so that concurrent writers rarely contend
+const stripes = 256
+
+// How an entry's score relates to the true score of the position
+type Bound uint8
+
+const (
+	// The score is exact
+	Exact Bound = iota
+
+	// The search failed high, the true score is at least Score
+	Lower
+
+	// The search failed low, the true score is at most Score
+	Upper
+)
+
+// A cached result, M is the move type stored as the best move
+type Entry[M any] struct {
+	Hash  uint64
+	Depth int
+	Bound Bound
+	Score int
+	Move  M
+}
+
+type slot[M any] struct {
+	entry Entry[M]
+	used  bool
+}
+
+// Each bucket holds a depth-preferred slot, which is only replaced
+// by entries searched at least as deep, and an always-replace slot
+// that takes everything else.
+type bucket[M any] struct {
+	deepest, recent slot[M]
+}
+
+// A Table is a fixed-size hash table of entries,
+// safe for concurrent use by multiple goroutines.
+type Table[M any] struct {
+	buckets []bucket[M]
+	mask    uint64
+	locks   [stripes]sync.Mutex
+}
+
+// Creates a table using at most megabytes of memory,
+// rounded down to a power of two number of buckets.
+func New[M any](megabytes int) *Table[M] {
+	size := uint64(unsafe.Sizeof(bucket[M]{}))
+	count := uint64(1)
+	for count*2*size <= uint64(megabytes)<<20 {
+		count *= 2
+	}
+
+	return &Table[M]{
+		buckets: make([]bucket[M], count),
+		mask:    count - 1,
+	}
+}
+
+// Returns the entry stored for hash, if any
+func (table *Table[M]) Probe(hash uint64) (Entry[M], bool) {
+	index := hash & table.mask
+	lock := &table.locks[index%stripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	bucket := &table.buckets[index]
+	if bucket.deepest.used && bucket.deepest.entry.Hash == hash {
+		return bucket.deepest.entry, true
+	}
+
+	if bucket.recent.used && bucket.recent.entry.Hash == hash {
+		return bucket.recent.entry, true
+	}
+
+	return Entry[M]{}, false
+}
+
+// Stores entry in the depth-preferred slot if it is for the same position
+// or searched at least as deep, moving the previous occupant to the
+// always-replace slot. Otherwise, entry goes in the always-replace slot.
+func (table *Table[M]) Store(entry Entry[M]) {
+	index := entry.Hash & table.mask
+	lock := &table.locks[index%stripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	bucket := &table.buckets[index]
+	deepest := bucket.deepest
+
+	if deepest.used && deepest.entry.Hash != entry.Hash && entry.Depth < deepest.entry.Depth {
+		bucket.recent = slot[M]{entry, true}
+		return
+	}
+
+	if deepest.used && deepest.entry.Hash != entry.Hash {
+		bucket.recent = deepest
+	} else if bucket.recent.entry.Hash == entry.Hash {
+		bucket.recent.used = false
+	}
+
+	bucket.deepest = slot[M]{entry, true}
+}
+
+// Removes all entries from the table
+func (table *Table[M]) Clear() {
+	for i := range table.locks {
+		table.locks[i].Lock()
+	}
+	defer func() {
+		for i := range table.locks {
+			table.locks[i].Unlock()
+		}
+	}()
+
+	clear(table.buckets)
+}
+
+// Number of entries the table can hold
+func (table *Table[M]) Capacity() int {
+	return 2 * len(table.buckets)
+}
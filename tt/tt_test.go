@@ -0,0 +1,130 @@
+package tt
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestNew(t *testing.T) {
+	for _, megabytes := range []int{0, 1, 3, 16} {
+		table := New[int](megabytes)
+		buckets := uint64(len(table.buckets))
+		size := uint64(unsafe.Sizeof(bucket[int]{}))
+
+		if buckets&(buckets-1) != 0 {
+			t.Errorf("expected a power of two buckets for %dMB, got %d", megabytes, buckets)
+		}
+
+		if buckets > 1 && buckets*size > uint64(megabytes)<<20 {
+			t.Errorf("expected at most %dMB, got %d bytes", megabytes, buckets*size)
+		}
+
+		if 2*buckets*size <= uint64(megabytes)<<20 {
+			t.Errorf("expected %dMB to fit more than %d buckets", megabytes, buckets)
+		}
+
+		if table.Capacity() != int(2*buckets) {
+			t.Errorf("expected capacity %d, got %d", 2*buckets, table.Capacity())
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	table := New[int](1)
+
+	if _, ok := table.Probe(42); ok {
+		t.Error("expected an empty table to miss")
+	}
+
+	stored := Entry[int]{Hash: 42, Depth: 3, Bound: Lower, Score: -15, Move: 7}
+	table.Store(stored)
+
+	entry, ok := table.Probe(42)
+	if !ok || entry != stored {
+		t.Errorf("expected %+v, got %+v (%v)", stored, entry, ok)
+	}
+
+	// Same bucket, different position
+	if _, ok := table.Probe(42 + uint64(len(table.buckets))); ok {
+		t.Error("expected a different hash in the same bucket to miss")
+	}
+
+	table.Clear()
+	if _, ok := table.Probe(42); ok {
+		t.Error("expected a cleared table to miss")
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	table := New[int](0)
+
+	deep := Entry[int]{Hash: 1, Depth: 5}
+	shallow := Entry[int]{Hash: 2, Depth: 2}
+	shallower := Entry[int]{Hash: 3, Depth: 1}
+	deeper := Entry[int]{Hash: 4, Depth: 6}
+
+	expect := func(t *testing.T, present []Entry[int], missing []Entry[int]) {
+		t.Helper()
+
+		for _, entry := range present {
+			if _, ok := table.Probe(entry.Hash); !ok {
+				t.Errorf("expected entry %d to be stored", entry.Hash)
+			}
+		}
+
+		for _, entry := range missing {
+			if _, ok := table.Probe(entry.Hash); ok {
+				t.Errorf("expected entry %d to be replaced", entry.Hash)
+			}
+		}
+	}
+
+	table.Store(deep)
+	table.Store(shallow)
+	t.Run("Shallow Entries Always Replace", func(t *testing.T) {
+		expect(t, []Entry[int]{deep, shallow}, nil)
+
+		table.Store(shallower)
+		expect(t, []Entry[int]{deep, shallower}, []Entry[int]{shallow})
+	})
+
+	t.Run("Deeper Entries Demote", func(t *testing.T) {
+		table.Store(deeper)
+		expect(t, []Entry[int]{deeper, deep}, []Entry[int]{shallower})
+	})
+
+	t.Run("Same Position Updates", func(t *testing.T) {
+		updated := Entry[int]{Hash: deep.Hash, Depth: 1, Score: 30}
+		table.Store(updated)
+
+		entry, ok := table.Probe(deep.Hash)
+		if !ok || entry != updated {
+			t.Errorf("expected %+v, got %+v (%v)", updated, entry, ok)
+		}
+		expect(t, []Entry[int]{deeper}, nil)
+	})
+}
+
+func TestConcurrent(t *testing.T) {
+	table := New[int](1)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				hash := uint64(worker*1000 + i)
+				table.Store(Entry[int]{Hash: hash, Depth: i, Score: int(hash)})
+
+				if entry, ok := table.Probe(hash); ok && entry.Score != int(hash) {
+					t.Errorf("expected score %d, got %d", hash, entry.Score)
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+}
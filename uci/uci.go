@@ -0,0 +1,407 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/msws/chess/board"
+	"github.com/msws/chess/search"
+	"github.com/msws/chess/tt"
+)
+
+const (
+	EngineName   = "Chess"
+	EngineAuthor = "MSWS"
+
+	// Largest transposition table the Hash option allows, in megabytes
+	maxHash = 1024
+)
+
+// An Engine speaks the Universal Chess Interface,
+// reading commands line by line and writing responses to out.
+type Engine struct {
+	out     io.Writer
+	outLock sync.Mutex
+
+	game    *board.Game
+	options map[string]string
+
+	// Kept between searches, so what was learnt on one move carries over to the next
+	table *tt.Table[board.Move]
+
+	stop      atomic.Bool
+	searching sync.WaitGroup
+	// Made by go infinite and go ponder, where bestmove may only be
+	// sent once stop (or ponderhit) closes it. Nil otherwise.
+	release chan struct{}
+}
+
+func NewEngine(out io.Writer) *Engine {
+	engine := &Engine{
+		out:     out,
+		options: map[string]string{},
+		table:   tt.New[board.Move](search.TableSize),
+	}
+	engine.newGame()
+
+	return engine
+}
+
+// Reads commands from in until either "quit" is received
+// or the input is exhausted.
+func (engine *Engine) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+
+	for scanner.Scan() {
+		if !engine.Handle(scanner.Text()) {
+			return nil
+		}
+	}
+
+	engine.stopSearch()
+	return scanner.Err()
+}
+
+// Handles a single command, returning false once the engine should quit.
+func (engine *Engine) Handle(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "uci":
+		engine.println("id name %s", EngineName)
+		engine.println("id author %s", EngineAuthor)
+		engine.println("option name Hash type spin default %d min 1 max %d", search.TableSize, maxHash)
+		engine.println("option name UCI_Chess960 type check default false")
+		engine.println("uciok")
+	case "isready":
+		engine.println("readyok")
+	case "ucinewgame":
+		engine.stopSearch()
+		engine.newGame()
+		engine.table.Clear()
+	case "position":
+		engine.stopSearch()
+		if err := engine.position(args); err != nil {
+			engine.println("info string %v", err)
+		}
+	case "go":
+		engine.stopSearch()
+		if err := engine.goCommand(args); err != nil {
+			engine.println("info string %v", err)
+		}
+	case "stop":
+		engine.stopSearch()
+	case "setoption":
+		// The search reads the options and table, so it can't run while they change
+		engine.stopSearch()
+		if err := engine.setOption(args); err != nil {
+			engine.println("info string %v", err)
+		}
+	case "quit":
+		engine.stopSearch()
+		return false
+	case "ponderhit":
+		engine.releaseBestMove()
+	case "debug", "register":
+		// Accepted, but not supported
+	default:
+		engine.println("info string unknown command: %s", command)
+	}
+
+	return true
+}
+
+func (engine *Engine) println(format string, args ...any) {
+	engine.outLock.Lock()
+	defer engine.outLock.Unlock()
+
+	fmt.Fprintf(engine.out, format+"\n", args...)
+}
+
+func (engine *Engine) newGame() {
+	game, err := board.FromFEN(board.START_POSITION)
+	if err != nil {
+		panic(err)
+	}
+
+	engine.game = game
+}
+
+func (engine *Engine) stopSearch() {
+	engine.stop.Store(true)
+	engine.releaseBestMove()
+	engine.searching.Wait()
+	engine.stop.Store(false)
+}
+
+func (engine *Engine) releaseBestMove() {
+	if engine.release != nil {
+		close(engine.release)
+		engine.release = nil
+	}
+}
+
+// position [startpos | fen <fen>] [moves <move>...]
+func (engine *Engine) position(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing position")
+	}
+
+	fen := board.START_POSITION
+	moveIndex := slices.Index(args, "moves")
+	if moveIndex == -1 {
+		moveIndex = len(args)
+	}
+
+	switch args[0] {
+	case "startpos":
+	case "fen":
+		fen = strings.Join(args[1:moveIndex], " ")
+	default:
+		return fmt.Errorf("unknown position type: %s", args[0])
+	}
+
+	// GUIs may omit the clocks, or send Shredder-FEN castling in Chess960
+	game, err := board.FromFENLenient(fen)
+	if err != nil {
+		return err
+	}
+
+	if engine.chess960() {
+		game.Chess960 = true
+	}
+
+	if moveIndex < len(args) {
+		for _, str := range args[moveIndex+1:] {
+			move, err := game.ParseUCIMove(str)
+			if err != nil {
+				return err
+			}
+			game.MakeMove(move)
+		}
+	}
+
+	engine.game = game
+	return nil
+}
+
+// go [perft <depth>] [depth <depth>] [movetime <ms>] [wtime <ms>] ...
+func (engine *Engine) goCommand(args []string) error {
+	if len(args) >= 1 && args[0] == "perft" {
+		if len(args) < 2 {
+			return fmt.Errorf("missing perft depth")
+		}
+		depth, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+		if depth < 1 {
+			return fmt.Errorf("invalid perft depth: %d", depth)
+		}
+		engine.perft(depth)
+		return nil
+	}
+
+	limits, err := engine.parseLimits(args)
+	if err != nil {
+		return err
+	}
+
+	limits.Stop = &engine.stop
+	limits.Info = engine.info
+	limits.Table = engine.table
+
+	game := engine.game
+	var release chan struct{}
+	if slices.Contains(args, "infinite") || slices.Contains(args, "ponder") {
+		release = make(chan struct{})
+		engine.release = release
+	}
+	engine.searching.Add(1)
+
+	go func() {
+		defer engine.searching.Done()
+
+		result := search.Search(game, limits)
+		if release != nil {
+			<-release
+		}
+
+		if len(result.PV) == 0 {
+			engine.println("bestmove 0000")
+			return
+		}
+
+		engine.println("bestmove %s", engine.formatMove(result.Move))
+	}()
+
+	return nil
+}
+
+func (engine *Engine) parseLimits(args []string) (search.Limits, error) {
+	limits := search.Limits{}
+	var remaining, increment time.Duration
+	movesToGo := 30
+
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		if name == "infinite" || name == "ponder" {
+			continue
+		}
+
+		if name == "searchmoves" {
+			// The moves run until the next argument that isn't one
+			for i+1 < len(args) {
+				move, err := engine.game.ParseUCIMove(args[i+1])
+				if err != nil {
+					break
+				}
+				limits.Moves = append(limits.Moves, move)
+				i++
+			}
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return limits, fmt.Errorf("missing value for %s", name)
+		}
+
+		i++
+		value, err := strconv.Atoi(args[i])
+		if err != nil {
+			return limits, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+
+		switch name {
+		case "depth":
+			limits.Depth = value
+		case "nodes":
+			limits.Nodes = value
+		case "movetime":
+			limits.MoveTime = time.Duration(value) * time.Millisecond
+		case "wtime", "btime":
+			if (name == "wtime") == (engine.game.Active == board.White) {
+				remaining = time.Duration(value) * time.Millisecond
+			}
+		case "winc", "binc":
+			if (name == "winc") == (engine.game.Active == board.White) {
+				increment = time.Duration(value) * time.Millisecond
+			}
+		case "movestogo":
+			if value > 0 {
+				movesToGo = value
+			}
+		}
+	}
+
+	if limits.MoveTime == 0 && remaining > 0 {
+		limits.MoveTime = remaining/time.Duration(movesToGo) + increment/2
+		if limits.MoveTime >= remaining {
+			limits.MoveTime = remaining / 2
+		}
+	}
+
+	return limits, nil
+}
+
+func (engine *Engine) info(result search.Result) {
+	score := fmt.Sprintf("cp %d", result.Score)
+	if result.IsMate() {
+		score = fmt.Sprintf("mate %d", result.MateIn())
+	}
+
+	pv := make([]string, len(result.PV))
+	for i, move := range result.PV {
+		pv[i] = engine.formatMove(move)
+	}
+
+	millis := result.Time.Milliseconds()
+	nps := int64(result.Nodes)
+	if millis > 0 {
+		nps = int64(result.Nodes) * 1000 / millis
+	}
+
+	engine.println("info depth %d score %s nodes %d time %d nps %d pv %s",
+		result.Depth, score, result.Nodes, millis, nps, strings.Join(pv, " "))
+}
+
+// Formats move for the GUI, writing castling as king-takes-rook
+// when the UCI_Chess960 option is enabled
+func (engine *Engine) formatMove(move board.Move) string {
+	if engine.chess960() {
+		return move.UCIChess960()
+	}
+
+	return move.UCI()
+}
+
+func (engine *Engine) perft(depth int) {
+	total := 0
+
+	for _, move := range engine.game.GetMoves() {
+		engine.game.MakeMove(move)
+		nodes := engine.game.Perft(depth - 1)
+		engine.game.UndoMove()
+
+		total += nodes
+		engine.println("%s: %d", engine.formatMove(move), nodes)
+	}
+
+	engine.println("")
+	engine.println("Nodes searched: %d", total)
+}
+
+// setoption name <id> [value <x>]
+func (engine *Engine) setOption(args []string) error {
+	if len(args) < 2 || args[0] != "name" {
+		return fmt.Errorf("malformed setoption")
+	}
+
+	valueIndex := slices.Index(args, "value")
+	if valueIndex == -1 {
+		engine.options[optionName(strings.Join(args[1:], " "))] = ""
+		return nil
+	}
+
+	name := optionName(strings.Join(args[1:valueIndex], " "))
+	value := strings.Join(args[valueIndex+1:], " ")
+
+	if name == "Hash" {
+		megabytes, err := strconv.Atoi(value)
+		if err != nil || megabytes < 1 || megabytes > maxHash {
+			return fmt.Errorf("invalid Hash size: %s", value)
+		}
+		engine.table = tt.New[board.Move](megabytes)
+	}
+
+	engine.options[name] = value
+	return nil
+}
+
+// Option names are case-insensitive, so the ones the engine
+// advertises are stored under their advertised spelling
+func optionName(name string) string {
+	for _, known := range []string{"Hash", "UCI_Chess960"} {
+		if strings.EqualFold(name, known) {
+			return known
+		}
+	}
+
+	return name
+}
+
+func (engine *Engine) chess960() bool {
+	return strings.EqualFold(engine.options["UCI_Chess960"], "true")
+}
@@ -0,0 +1,345 @@
+package uci
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msws/chess/board"
+)
+
+// Runs each command, waiting for any search to complete
+func runCommands(t *testing.T, commands ...string) (*Engine, string) {
+	var out bytes.Buffer
+	engine := NewEngine(&out)
+
+	for _, command := range commands {
+		if !engine.Handle(command) {
+			t.Fatalf("engine quit on %q", command)
+		}
+		engine.searching.Wait()
+	}
+
+	return engine, out.String()
+}
+
+func TestHandshake(t *testing.T) {
+	_, out := runCommands(t, "uci", "isready")
+
+	for _, expected := range []string{"id name " + EngineName, "id author " + EngineAuthor, "option name Hash type spin default 16 min 1 max 1024",
+		"option name UCI_Chess960 type check default false", "uciok", "readyok"} {
+		if !strings.Contains(out, expected+"\n") {
+			t.Errorf("expected output to contain %q, got %q", expected, out)
+		}
+	}
+}
+
+func TestPosition(t *testing.T) {
+	tests := map[string]struct {
+		command  string
+		expected string
+	}{
+		"Start Position": {
+			command:  "position startpos",
+			expected: board.START_POSITION,
+		},
+		"Start Position Moves": {
+			command:  "position startpos moves e2e4 e7e5 g1f3",
+			expected: "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+		},
+		"FEN": {
+			command:  "position fen 8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			expected: "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		},
+		"FEN Without Clocks": {
+			command:  "position fen 8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - moves a5a4",
+			expected: "8/2p5/3p4/1P5r/KR3p1k/8/4P1P1/8 b - - 1 1",
+		},
+		"Shredder FEN": {
+			command:  "position fen r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1",
+			expected: "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+		},
+		"Castling": {
+			command:  "position fen r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1 moves e1g1 e8c8",
+			expected: "2kr3r/8/8/8/8/8/8/R4RK1 w - - 2 2",
+		},
+		"Promotion": {
+			command:  "position fen 8/3P4/8/8/8/8/8/k1K5 w - - 0 1 moves d7d8n",
+			expected: "3N4/8/8/8/8/8/8/k1K5 b - - 0 1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			engine, out := runCommands(t, test.command)
+
+			if out != "" {
+				t.Errorf("unexpected output: %q", out)
+			}
+
+			if engine.game.ToFEN() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, engine.game.ToFEN())
+			}
+		})
+	}
+}
+
+func TestIllegalPosition(t *testing.T) {
+	engine, out := runCommands(t, "position startpos moves e2e5")
+
+	if !strings.HasPrefix(out, "info string") {
+		t.Errorf("expected an info string, got %q", out)
+	}
+
+	if engine.game.ToFEN() != board.START_POSITION {
+		t.Errorf("expected position to be unchanged, got %v", engine.game.ToFEN())
+	}
+}
+
+func TestGo(t *testing.T) {
+	t.Run("Legal", func(t *testing.T) {
+		_, out := runCommands(t, "position startpos", "go depth 2")
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if !strings.HasPrefix(lines[0], "info depth 1 ") {
+			t.Errorf("expected search info, got %q", lines[0])
+		}
+
+		line := lines[len(lines)-1]
+		if !strings.HasPrefix(line, "bestmove ") {
+			t.Fatalf("expected bestmove, got %q", out)
+		}
+
+		game, err := board.FromFEN(board.START_POSITION)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := game.ParseUCIMove(strings.TrimPrefix(line, "bestmove ")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Mated", func(t *testing.T) {
+		_, out := runCommands(t, "position fen 1R3k2/2R5/8/8/8/1K6/8/8 b - - 0 1", "go")
+
+		if out != "bestmove 0000\n" {
+			t.Errorf("expected null bestmove, got %q", out)
+		}
+	})
+
+	t.Run("Mate Score", func(t *testing.T) {
+		_, out := runCommands(t, "position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go depth 2")
+
+		if !strings.Contains(out, "score mate 1 ") {
+			t.Errorf("expected mate score, got %q", out)
+		}
+
+		if !strings.HasSuffix(out, "bestmove a1a8\n") {
+			t.Errorf("expected bestmove a1a8, got %q", out)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		var out bytes.Buffer
+		engine := NewEngine(&out)
+
+		err := engine.Run(strings.NewReader("go infinite\nstop\nquit\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), "bestmove ") {
+			t.Errorf("expected bestmove after stop, got %q", out.String())
+		}
+	})
+
+	t.Run("Perft", func(t *testing.T) {
+		_, out := runCommands(t, "position startpos", "go perft 2")
+
+		if !strings.Contains(out, "e2e4: 20\n") {
+			t.Errorf("expected divide for e2e4, got %q", out)
+		}
+
+		if !strings.HasSuffix(out, "Nodes searched: 400\n") {
+			t.Errorf("expected 400 nodes, got %q", out)
+		}
+	})
+
+	t.Run("Perft Depth Zero", func(t *testing.T) {
+		_, out := runCommands(t, "position startpos", "go perft 0", "go perft -2")
+
+		if strings.Count(out, "info string invalid perft depth") != 2 || strings.Contains(out, "Nodes searched") {
+			t.Errorf("expected an error for each depth below 1, got %q", out)
+		}
+	})
+
+	t.Run("Chess960 Castling", func(t *testing.T) {
+		_, out := runCommands(t,
+			"setoption name UCI_Chess960 value true",
+			"position fen r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			"go perft 1")
+
+		if !strings.Contains(out, "e1h1: 1\n") || !strings.Contains(out, "e1a1: 1\n") {
+			t.Errorf("expected king-takes-rook castling, got %q", out)
+		}
+	})
+
+	t.Run("Chess960 Position", func(t *testing.T) {
+		engine, _ := runCommands(t,
+			"setoption name UCI_Chess960 value true",
+			"position fen rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w HAha - 0 1 moves b1h1 b8a8")
+
+		expected := "2kr3r/pppppppp/8/8/8/8/PPPPPPPP/R4RK1 w - - 2 2"
+		if engine.game.ToFEN() != expected {
+			t.Errorf("expected %v, got %v", expected, engine.game.ToFEN())
+		}
+	})
+}
+
+func TestInfinite(t *testing.T) {
+	for _, command := range []string{"go infinite depth 1", "go ponder depth 1"} {
+		t.Run(command, func(t *testing.T) {
+			var out bytes.Buffer
+			engine := NewEngine(&out)
+			output := func() string {
+				engine.outLock.Lock()
+				defer engine.outLock.Unlock()
+				return out.String()
+			}
+
+			engine.Handle("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+			engine.Handle(command)
+			time.Sleep(50 * time.Millisecond)
+
+			if strings.Contains(output(), "bestmove") {
+				t.Fatalf("expected bestmove to wait for stop, got %q", output())
+			}
+
+			engine.Handle("stop")
+			if !strings.HasSuffix(output(), "bestmove a1a8\n") {
+				t.Errorf("expected bestmove after stop, got %q", output())
+			}
+		})
+	}
+
+	t.Run("Ponder Hit", func(t *testing.T) {
+		var out bytes.Buffer
+		engine := NewEngine(&out)
+
+		engine.Handle("go ponder depth 1")
+		engine.Handle("ponderhit")
+		engine.searching.Wait()
+
+		if !strings.Contains(out.String(), "bestmove ") {
+			t.Errorf("expected bestmove after ponderhit, got %q", out.String())
+		}
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	engine := NewEngine(&bytes.Buffer{})
+
+	t.Run("Move Time", func(t *testing.T) {
+		limits, err := engine.parseLimits([]string{"movetime", "1500", "depth", "4"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if limits.MoveTime != 1500*time.Millisecond || limits.Depth != 4 {
+			t.Errorf("unexpected limits %+v", limits)
+		}
+	})
+
+	t.Run("Clock", func(t *testing.T) {
+		limits, err := engine.parseLimits([]string{"wtime", "30000", "btime", "1000", "winc", "1000", "movestogo", "10"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if limits.MoveTime != 3500*time.Millisecond {
+			t.Errorf("expected %v, got %v", 3500*time.Millisecond, limits.MoveTime)
+		}
+	})
+
+	t.Run("Search Moves", func(t *testing.T) {
+		limits, err := engine.parseLimits([]string{"searchmoves", "e2e4", "d2d4", "depth", "3"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(limits.Moves) != 2 || limits.Moves[1].UCI() != "d2d4" || limits.Depth != 3 {
+			t.Errorf("unexpected limits %+v", limits)
+		}
+	})
+
+	t.Run("Missing Value", func(t *testing.T) {
+		if _, err := engine.parseLimits([]string{"depth"}); err == nil {
+			t.Error("expected error for missing depth")
+		}
+	})
+}
+
+func TestHashOption(t *testing.T) {
+	engine, out := runCommands(t, "setoption name Hash value 1")
+	if out != "" {
+		t.Errorf("expected Hash to be accepted, got %q", out)
+	}
+
+	table := engine.table
+	engine.Handle("position startpos")
+	engine.Handle("go depth 2")
+	engine.searching.Wait()
+
+	if engine.table != table {
+		t.Error("expected the table to be kept between searches")
+	}
+
+	if _, ok := table.Probe(engine.game.Hash()); !ok {
+		t.Error("expected the search to use the engine's table")
+	}
+
+	_, out = runCommands(t, "setoption name Hash value 0")
+	if !strings.Contains(out, "invalid Hash size") {
+		t.Errorf("expected an error for a zero Hash, got %q", out)
+	}
+}
+
+func TestSetOption(t *testing.T) {
+	engine, _ := runCommands(t, "setoption name Clear Hash", "setoption name Skill Level value 20")
+
+	if value, ok := engine.options["Clear Hash"]; !ok || value != "" {
+		t.Errorf("expected button option to be set, got %q (%v)", value, ok)
+	}
+
+	if engine.options["Skill Level"] != "20" {
+		t.Errorf("expected Skill Level to be 20, got %q", engine.options["Skill Level"])
+	}
+
+	t.Run("Case Insensitive", func(t *testing.T) {
+		engine, _ := runCommands(t,
+			"setoption name uci_chess960 value true",
+			"position fen r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+
+		if !engine.chess960() || !engine.game.Chess960 {
+			t.Error("expected UCI_Chess960 to be set regardless of case")
+		}
+	})
+
+	t.Run("During Search", func(t *testing.T) {
+		var out bytes.Buffer
+		engine := NewEngine(&out)
+
+		engine.Handle("go infinite")
+		engine.Handle("setoption name UCI_Chess960 value true")
+
+		if !strings.Contains(out.String(), "bestmove ") {
+			t.Errorf("expected the search to stop before the option changed, got %q", out.String())
+		}
+
+		if !engine.chess960() {
+			t.Error("expected UCI_Chess960 to be set")
+		}
+	})
+}